@@ -0,0 +1,20 @@
+package flagship
+
+import (
+	"context"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/client"
+)
+
+type visitorContextKey struct{}
+
+// ContextWithVisitor returns a copy of the context carrying the visitor
+func ContextWithVisitor(ctx context.Context, visitor *client.Visitor) context.Context {
+	return context.WithValue(ctx, visitorContextKey{}, visitor)
+}
+
+// VisitorFromContext returns the visitor stored in the context, such as the one set by the middleware package for each request
+func VisitorFromContext(ctx context.Context) (*client.Visitor, bool) {
+	visitor, ok := ctx.Value(visitorContextKey{}).(*client.Visitor)
+	return visitor, ok && visitor != nil
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 
 	"github.com/flagship-io/flagship-go-sdk/v2"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/extractor"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,7 +28,18 @@ func main() {
 		log.Fatalf("error when starting Flagship: %v", err)
 	}
 
+	// Create a Flagship visitor for each request, identified by a cookie and targeted with the request user agent
+	fsMiddleware := middleware.New(fsClient,
+		middleware.ContextExtractor(extractor.NewContextExtractor(extractor.UserAgent(), extractor.AcceptLanguage())),
+		middleware.SendPageView(true))
+
 	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		fsMiddleware.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.Request = r
+			c.Next()
+		})(c.Writer, c.Request)
+	})
 
 	router.Static("/static", "public")
 
@@ -39,24 +52,11 @@ func main() {
 
 	router.GET("/", func(c *gin.Context) {
 
-		// TODO: use your own visitor ID from cookie, session, database, uuid, ...
-		flagshipVisitorID := "visitorid_1"
-
-		// TODO: use your own visitor context (data that you want to target your visitor with) from cookie, session, database, ...
-		flagshipVisitorContext := map[string]interface{}{
-			"device": "firefox",
-		}
-
-		// Create a Flagship visitor with an ID and a context
-		fsVisitor, err := fsClient.NewVisitor(flagshipVisitorID, flagshipVisitorContext)
-		if err != nil {
-			log.Fatalf("error when creating Flagship visitor: %v", err)
-		}
-
-		// Fetch flags and metadata from Flagship
-		err = fsVisitor.SynchronizeModifications()
-		if err != nil {
-			log.Fatalf("error when creating synchronizing Flagship flags: %v", err)
+		// Get the Flagship visitor created and synchronized by the middleware
+		fsVisitor, ok := flagship.VisitorFromContext(c.Request.Context())
+		if !ok {
+			c.String(http.StatusInternalServerError, "Flagship visitor not created")
+			return
 		}
 
 		// Get flags from Flagship to customize the banner (banner.html)
@@ -74,7 +74,6 @@ func main() {
 			"flagship": gin.H{
 				"btnStyle":  fmt.Sprintf("style=\"color:%s;background-color:%s\"", valueTxtColor, valueBtnColor),
 				"btnText":   valueBtnText,
-				"error":     err,
 				"variables": variablesObj,
 			},
 		})
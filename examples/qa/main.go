@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"encoding/json"
 	"fmt"
@@ -22,7 +23,9 @@ import (
 
 	"github.com/flagship-io/flagship-go-sdk/v2"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/client"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/extractor"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/middleware"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -30,7 +33,7 @@ import (
 )
 
 var fsClients = make(map[string]*client.Client)
-var fsVisitors = make(map[string]*client.Visitor)
+var fsMiddlewares = make(map[string]*middleware.Middleware)
 var segmentClient analytics.Client
 var memLog = &bytes.Buffer{}
 
@@ -40,6 +43,9 @@ type FsSession struct {
 	APIKey          string
 	UseBucketing    bool //true
 	VisitorID       string
+	IsAuthenticated bool
+	AnonymousID     string
+	Context         map[string]interface{}
 	Timeout         int
 	PollingInterval int
 	SegmentAPIKey   string
@@ -133,21 +139,125 @@ func setFsSession(c *gin.Context, fsS *FsSession) {
 	}
 }
 
-func returnVisitor(c *gin.Context, fsVisitor *client.Visitor, err error) {
+func returnVisitor(c *gin.Context, fsVisitor *client.Visitor) {
 	flagInfos := fsVisitor.GetAllModifications()
 
 	resp := gin.H{
 		"flags":       flagInfos,
-		"visitorId":   fsVisitor.ID,
-		"anonymousId": fsVisitor.AnonymousID,
+		"visitorId":   fsVisitor.GetID(),
+		"anonymousId": fsVisitor.GetAnonymousID(),
 	}
-	if err != nil {
-		resp["error"] = err.Error()
+	// The middleware keeps the visitor with the default flag values if the synchronization fails
+	if fetchStatus := fsVisitor.GetFetchStatus(); fetchStatus.Status != client.FETCH_STATUS_FETCHED {
+		resp["error"] = fmt.Sprintf("Flags not fetched: %s", fetchStatus.Reason)
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
+// fsSessionKey is the request context key of the session read by the middleware
+type fsSessionKey struct{}
+
+func getRequestFsSession(r *http.Request) *FsSession {
+	return r.Context().Value(fsSessionKey{}).(*FsSession)
+}
+
+// newMiddleware creates the middleware of an environment client. The visitor is created from the one saved in the session
+func newMiddleware(fsClient *client.Client) *middleware.Middleware {
+	sessionContext := extractor.ExtractorFunc(func(r *http.Request, context model.Context) {
+		for key, value := range getRequestFsSession(r).Context {
+			context[key] = value
+		}
+	})
+
+	return middleware.New(fsClient,
+		middleware.RequestVisitor(func(r *http.Request) (string, []client.VisitorOptionBuilder) {
+			fsSession := getRequestFsSession(r)
+			return fsSession.VisitorID, []client.VisitorOptionBuilder{
+				client.WithAuthenticated(fsSession.IsAuthenticated),
+				client.WithAnonymousID(fsSession.AnonymousID),
+			}
+		}),
+		middleware.ContextExtractor(extractor.NewContextExtractor(sessionContext)),
+		middleware.ErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) bool {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(gin.H{"error": err.Error()})
+			return false
+		}))
+}
+
+// requireVisitor aborts the request if no visitor is saved in the session
+func requireVisitor(c *gin.Context) {
+	fsSession := getFsSession(c)
+	if fsSession == nil || fsMiddlewares[fsSession.EnvID] == nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
+		return
+	}
+	if fsSession.VisitorID == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "FS Visitor not initialized"})
+	}
+}
+
+// useVisitor runs the middleware of the session environment, which creates and synchronizes the visitor of the session
+func useVisitor(c *gin.Context) {
+	if requireVisitor(c); c.IsAborted() {
+		return
+	}
+
+	fsSession := getFsSession(c)
+	r := c.Request.WithContext(context.WithValue(c.Request.Context(), fsSessionKey{}, fsSession))
+	fsMiddlewares[fsSession.EnvID].HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Request = r
+		c.Next()
+	})(c.Writer, r)
+	c.Abort()
+}
+
+// updateVisitor returns a handler updating the visitor of the session before the middleware creates it.
+// The session is saved with the created visitor by saveVisitor
+func updateVisitor(update func(c *gin.Context, fsSession *FsSession) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fsSession := getFsSession(c)
+		if fsSession == nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
+			return
+		}
+
+		newSession := *fsSession
+		if !update(c, &newSession) {
+			c.Abort()
+			return
+		}
+		sessions.Default(c).Set("fs_session", &newSession)
+	}
+}
+
+// saveVisitor saves the anonymous ID of the visitor created by the middleware in the session, and returns the visitor
+func saveVisitor(c *gin.Context) {
+	fsVisitor, ok := getFsVisitor(c)
+	if !ok {
+		return
+	}
+
+	fsSession := *getFsSession(c)
+	if anonymousID := fsVisitor.GetAnonymousID(); anonymousID != nil {
+		fsSession.AnonymousID = *anonymousID
+	}
+	setFsSession(c, &fsSession)
+
+	returnVisitor(c, fsVisitor)
+}
+
+// getFsVisitor returns the visitor created by the middleware
+func getFsVisitor(c *gin.Context) (*client.Visitor, bool) {
+	fsVisitor, ok := flagship.VisitorFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "FS Visitor not initialized"})
+	}
+	return fsVisitor, ok
+}
+
 func main() {
 	log.Println("Setting log level")
 	logging.SetLevel(logrus.DebugLevel)
@@ -230,6 +340,7 @@ func main() {
 			}
 		}
 		fsClients[json.EnvironmentID] = fsClient
+		fsMiddlewares[json.EnvironmentID] = newMiddleware(fsClient)
 		setFsSession(c, &FsSession{
 			EnvID:           json.EnvironmentID,
 			APIKey:          json.APIKey,
@@ -242,198 +353,128 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	visitorRoutes := router.Group("/", useVisitor)
+
 	router.GET("/visitor", func(c *gin.Context) {
 		fsSession := getFsSession(c)
 		if fsSession == nil || fsSession.VisitorID == "" {
-			c.JSON(http.StatusNotFound, gin.H{
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
 				"message": "visitor not initialized",
 			})
-			return
 		}
-		visitor, ok := fsVisitors[fsSession.EnvID+"-"+fsSession.VisitorID]
+	}, useVisitor, func(c *gin.Context) {
+		fsVisitor, ok := getFsVisitor(c)
 		if !ok {
-			c.JSON(http.StatusNotFound, gin.H{
-				"message": "visitor not initialized",
-			})
 			return
 		}
 		c.JSON(http.StatusOK, FSVisitorInfo{
-			VisitorID: visitor.ID,
-			Context:   visitor.Context,
+			VisitorID: fsVisitor.GetID(),
+			Context:   fsVisitor.GetContext(),
 		})
 	})
 
-	router.PUT("/visitor", func(c *gin.Context) {
+	// The visitor routes update the visitor of the session, which is then created and synchronized once by the middleware
+	router.PUT("/visitor", updateVisitor(func(c *gin.Context, fsSession *FsSession) bool {
 		var json FSVisitorInfo
 		if err := c.ShouldBindJSON(&json); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
+			return false
 		}
 
-		fsSession := getFsSession(c)
-		fsClient := fsClients[fsSession.EnvID]
-		if fsClient == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
-			return
-		}
-
-		fsVisitor, err := fsClient.NewVisitor(json.VisitorID, json.Context, client.WithAuthenticated(json.IsAuthenticated))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+		// An authenticated visitor gets a new anonymous ID
+		fsSession.VisitorID = json.VisitorID
+		fsSession.IsAuthenticated = json.IsAuthenticated
+		fsSession.AnonymousID = ""
+		fsSession.Context = json.Context
+		return true
+	}), useVisitor, saveVisitor)
 
-		err = fsVisitor.SynchronizeModifications()
-		fsVisitors[fsSession.EnvID+"-"+json.VisitorID] = fsVisitor
-		setFsSession(c, &FsSession{
-			EnvID:         fsSession.EnvID,
-			APIKey:        fsSession.APIKey,
-			UseBucketing:  fsSession.UseBucketing,
-			VisitorID:     json.VisitorID,
-			SegmentAPIKey: fsSession.SegmentAPIKey,
-		})
-
-		returnVisitor(c, fsVisitor, err)
-	})
-
-	router.PUT("/authenticate", func(c *gin.Context) {
+	router.PUT("/authenticate", requireVisitor, updateVisitor(func(c *gin.Context, fsSession *FsSession) bool {
 		var json FSVisitorAuthInfo
 		if err := c.ShouldBindJSON(&json); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		fsSession := getFsSession(c)
-		fsClient, _ := fsClients[fsSession.EnvID]
-		if fsClient == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
-			return
+			return false
 		}
 
-		fsVisitor, ok := fsVisitors[fsSession.EnvID+"-"+fsSession.VisitorID]
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": fmt.Errorf("Visitor ID %v not found", fsSession.VisitorID),
-			})
-			return
+		// The anonymous ID of an authenticated visitor is kept
+		if !fsSession.IsAuthenticated {
+			fsSession.AnonymousID = fsSession.VisitorID
 		}
+		fsSession.VisitorID = json.NewVisitorID
+		fsSession.IsAuthenticated = true
+		return true
+	}), useVisitor, saveVisitor)
 
-		err := fsVisitor.Authenticate(json.NewVisitorID, nil, true)
-
-		fsVisitors[fsSession.EnvID+"-"+fsVisitor.ID] = fsVisitor
-		setFsSession(c, &FsSession{
-			EnvID:        fsSession.EnvID,
-			APIKey:       fsSession.APIKey,
-			UseBucketing: fsSession.UseBucketing,
-			VisitorID:    fsVisitor.ID,
-		})
-
-		returnVisitor(c, fsVisitor, err)
-	})
-
-	router.PUT("/unauthenticate", func(c *gin.Context) {
+	router.PUT("/unauthenticate", requireVisitor, updateVisitor(func(c *gin.Context, fsSession *FsSession) bool {
 		var json FSVisitorUnauthInfo
 		if err := c.ShouldBindJSON(&json); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
+			return false
 		}
 
-		fsSession := getFsSession(c)
-		fsClient, _ := fsClients[fsSession.EnvID]
-		if fsClient == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
-			return
+		if fsSession.IsAuthenticated {
+			fsSession.VisitorID = fsSession.AnonymousID
+			fsSession.IsAuthenticated = false
+			fsSession.AnonymousID = ""
 		}
+		return true
+	}), useVisitor, saveVisitor)
 
-		fsVisitor, ok := fsVisitors[fsSession.EnvID+"-"+fsSession.VisitorID]
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": fmt.Errorf("Visitor ID %v not found", fsSession.VisitorID),
-			})
-			return
-		}
-
-		err := fsVisitor.Unauthenticate(nil, true)
-
-		fsVisitors[fsSession.EnvID+"-"+fsVisitor.ID] = fsVisitor
-		setFsSession(c, &FsSession{
-			EnvID:        fsSession.EnvID,
-			APIKey:       fsSession.APIKey,
-			UseBucketing: fsSession.UseBucketing,
-			VisitorID:    fsVisitor.ID,
-		})
-
-		returnVisitor(c, fsVisitor, err)
-	})
-
-	router.PUT("/visitor/context/:key", func(c *gin.Context) {
+	router.PUT("/visitor/context/:key", requireVisitor, updateVisitor(func(c *gin.Context, fsSession *FsSession) bool {
 		var key = c.Param("key")
 		var json FSUpdateContextInfo
 		if err := c.ShouldBindJSON(&json); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
+			return false
 		}
 
 		if key == "" || json.Type == "" || json.Value == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing context key, type or value"})
-			return
-		}
-
-		fsSession := getFsSession(c)
-		fsClient := fsClients[fsSession.EnvID]
-		if fsClient == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
-			return
-		}
-
-		fsVisitor := fsVisitors[fsSession.EnvID+"-"+fsSession.VisitorID]
-		if fsVisitor == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Visitor not initialized"})
-			return
+			return false
 		}
 
+		var value interface{}
 		var err error
 		switch json.Type {
 		case "bool":
-			defVal, castErr := strconv.ParseBool(json.Value)
-			if castErr != nil {
-				err = castErr
-				break
-			}
-			err = fsVisitor.UpdateContextKey(key, defVal)
+			value, err = strconv.ParseBool(json.Value)
 		case "number":
-			defVal, castErr := strconv.ParseFloat(json.Value, 64)
-			if castErr != nil {
-				err = castErr
-				break
-			}
-			err = fsVisitor.UpdateContextKey(key, defVal)
+			value, err = strconv.ParseFloat(json.Value, 64)
 		case "string":
-			err = fsVisitor.UpdateContextKey(key, json.Value)
-
+			value = json.Value
 		default:
 			err = fmt.Errorf("Context key type %v not handled", json.Type)
 		}
 
-		if err == nil {
-			err = fsVisitor.SynchronizeModifications()
-		}
-
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		} else {
-			flagInfos := fsVisitor.GetAllModifications()
-			c.JSON(http.StatusOK, gin.H{
-				"visitorId": fsVisitor.ID,
-				"context":   fsVisitor.Context,
-				"flags":     flagInfos,
-			})
+			return false
 		}
 
+		visitorContext := map[string]interface{}{}
+		for k, v := range fsSession.Context {
+			visitorContext[k] = v
+		}
+		visitorContext[key] = value
+		fsSession.Context = visitorContext
+		return true
+	}), useVisitor, func(c *gin.Context) {
+		fsVisitor, ok := getFsVisitor(c)
+		if !ok {
+			return
+		}
+
+		setFsSession(c, getFsSession(c))
+		flagInfos := fsVisitor.GetAllModifications()
+		c.JSON(http.StatusOK, gin.H{
+			"visitorId": fsVisitor.GetID(),
+			"context":   fsVisitor.GetContext(),
+			"flags":     flagInfos,
+		})
 	})
 
 	//router.LoadHTMLFiles("templates/template1.html", "templates/template2.html")
-	router.GET("/flag/:name", func(c *gin.Context) {
+	visitorRoutes.GET("/flag/:name", func(c *gin.Context) {
 		var flag = c.Param("name")
 		var flagType = c.Query("type")
 		var activate = c.Query("activate")
@@ -444,16 +485,8 @@ func main() {
 			return
 		}
 
-		fsSession := getFsSession(c)
-		fsClient := fsClients[fsSession.EnvID]
-		if fsClient == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
-			return
-		}
-
-		fsVisitor := fsVisitors[fsSession.EnvID+"-"+fsSession.VisitorID]
-		if fsVisitor == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Visitor not initialized"})
+		fsVisitor, ok := getFsVisitor(c)
+		if !ok {
 			return
 		}
 
@@ -516,7 +549,7 @@ func main() {
 		c.JSON(status, gin.H{"value": value, "error": errString})
 	})
 
-	router.GET("/flag/:name/activate", func(c *gin.Context) {
+	visitorRoutes.GET("/flag/:name/activate", func(c *gin.Context) {
 		var flag = c.Param("name")
 
 		if flag == "" {
@@ -524,16 +557,8 @@ func main() {
 			return
 		}
 
-		fsSession := getFsSession(c)
-		fsClient := fsClients[fsSession.EnvID]
-		if fsClient == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
-			return
-		}
-
-		fsVisitor := fsVisitors[fsSession.EnvID+"-"+fsSession.VisitorID]
-		if fsVisitor == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Visitor not initialized"})
+		fsVisitor, ok := getFsVisitor(c)
+		if !ok {
 			return
 		}
 
@@ -548,7 +573,7 @@ func main() {
 		})
 	})
 
-	router.GET("/flag/:name/info", func(c *gin.Context) {
+	visitorRoutes.GET("/flag/:name/info", func(c *gin.Context) {
 		var flag = c.Param("name")
 
 		if flag == "" {
@@ -556,16 +581,8 @@ func main() {
 			return
 		}
 
-		fsSession := getFsSession(c)
-		fsClient := fsClients[fsSession.EnvID]
-		if fsClient == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
-			return
-		}
-
-		fsVisitor := fsVisitors[fsSession.EnvID+"-"+fsSession.VisitorID]
-		if fsVisitor == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Visitor not initialized"})
+		fsVisitor, ok := getFsVisitor(c)
+		if !ok {
 			return
 		}
 
@@ -576,12 +593,13 @@ func main() {
 		}
 
 		// Track segment
+		fsSession := getFsSession(c)
 		if fsSession.SegmentAPIKey != "" {
 			segmentClient = analytics.New(fsSession.SegmentAPIKey)
 			defer segmentClient.Close()
 
 			data := analytics.Track{
-				UserId: fsVisitor.ID,
+				UserId: fsVisitor.GetID(),
 				Event:  "Flagship_Source_Go",
 				Properties: analytics.NewProperties().
 					Set("cid", modifInfos.CampaignID).
@@ -598,28 +616,15 @@ func main() {
 	})
 
 	//router.LoadHTMLFiles("templates/template1.html", "templates/template2.html")
-	router.POST("/hit", func(c *gin.Context) {
+	visitorRoutes.POST("/hit", func(c *gin.Context) {
 		var json FSHitInfo
 		if err := c.ShouldBindJSON(&json); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		fsSession := getFsSession(c)
-		if fsSession == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Session not initialized"})
-			return
-		}
-
-		fsClient := fsClients[fsSession.EnvID]
-		if fsClient == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Client not initialized"})
-			return
-		}
-
-		fsVisitor := fsVisitors[fsSession.EnvID+"-"+fsSession.VisitorID]
-		if fsVisitor == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "FS Visitor not initialized"})
+		fsVisitor, ok := getFsVisitor(c)
+		if !ok {
 			return
 		}
 
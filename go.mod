@@ -9,6 +9,7 @@ require (
 	github.com/flagship-io/flagship-proto v0.0.15
 	github.com/go-redis/redis/v8 v8.0.0-beta.5
 	github.com/kr/pretty v0.3.0 // indirect
+	github.com/oschwald/maxminddb-golang v1.8.0
 	github.com/rogpeppe/go-internal v1.8.0 // indirect
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.8.1
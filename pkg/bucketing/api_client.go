@@ -17,12 +17,15 @@ var apiLogger = logging.CreateLogger("Bucketing API")
 
 // APIClient represents the API client informations
 type APIClient struct {
-	url         string
-	envID       string
-	apiKey      string
-	timeout     time.Duration
-	retries     int
-	httpRequest utils.HTTPClientInterface
+	url               string
+	envID             string
+	apiKey            string
+	timeout           time.Duration
+	retries           int
+	httpRequest       utils.HTTPClientInterface
+	signatureVerifier SignatureVerifier
+	signatureHeader   string
+	signatureFile     string
 }
 
 // APIUrl sets http client base URL
@@ -53,6 +56,28 @@ func Retries(retries int) func(r *APIClient) {
 	}
 }
 
+// SignatureVerification enables the verification of the bucketing configuration signature
+func SignatureVerification(verifier SignatureVerifier) func(r *APIClient) {
+	return func(r *APIClient) {
+		r.signatureVerifier = verifier
+	}
+}
+
+// SignatureHeader sets the response header containing the base64 encoded configuration signature
+func SignatureHeader(header string) func(r *APIClient) {
+	return func(r *APIClient) {
+		r.signatureHeader = header
+	}
+}
+
+// SignatureFile sets the path, relative to the API url, of a detached file containing the base64 encoded configuration signature.
+// When set, the signature is read from this file instead of the response header
+func SignatureFile(path string) func(r *APIClient) {
+	return func(r *APIClient) {
+		r.signatureFile = path
+	}
+}
+
 // NewAPIClient creates a bucketing API Client to poll bucketing infos
 func NewAPIClient(envID string, params ...func(*APIClient)) *APIClient {
 	res := APIClient{
@@ -78,6 +103,10 @@ func NewAPIClient(envID string, params ...func(*APIClient)) *APIClient {
 		res.timeout = defaultTimeout
 	}
 
+	if res.signatureHeader == "" {
+		res.signatureHeader = defaultSignatureHeader
+	}
+
 	res.httpRequest = utils.NewHTTPClient(res.url, utils.HTTPOptions{
 		Timeout: res.timeout,
 		Headers: headers,
@@ -101,6 +130,14 @@ func (r *APIClient) GetConfiguration() (*bucketingProto.Bucketing_BucketingRespo
 		return nil, fmt.Errorf("Error when calling Bucketing API : %v", err)
 	}
 
+	if r.signatureVerifier != nil {
+		err = r.verifySignature(resp)
+		if err != nil {
+			apiLogger.Errorf("Bucketing configuration signature verification failed: %v", err)
+			return nil, err
+		}
+	}
+
 	conf := &bucketingProto.Bucketing_BucketingResponse{}
 	err = (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(resp.Body, conf)
 
@@ -110,3 +147,26 @@ func (r *APIClient) GetConfiguration() (*bucketingProto.Bucketing_BucketingRespo
 
 	return conf, nil
 }
+
+// verifySignature checks the signature of the raw configuration body from the response header or the detached file
+func (r *APIClient) verifySignature(resp *utils.HTTPResponse) error {
+	rawSignature := resp.Headers.Get(r.signatureHeader)
+
+	if r.signatureFile != "" {
+		sigResp, err := r.httpRequest.Call(r.signatureFile, "GET", nil, nil)
+		if err != nil {
+			return fmt.Errorf("Error when getting configuration signature file : %v", err)
+		}
+		if sigResp.StatusCode != 200 {
+			return fmt.Errorf("Error when getting configuration signature file : status code %d", sigResp.StatusCode)
+		}
+		rawSignature = string(sigResp.Body)
+	}
+
+	signature, err := decodeSignature(rawSignature)
+	if err != nil {
+		return err
+	}
+
+	return r.signatureVerifier.Verify(resp.Body, signature)
+}
@@ -0,0 +1,111 @@
+package bucketing
+
+import (
+	"sort"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// getValueType returns the JSON type name of a flag value
+func getValueType(value *structpb.Value) string {
+	switch value.GetKind().(type) {
+	case *structpb.Value_BoolValue:
+		return "bool"
+	case *structpb.Value_NumberValue:
+		return "number"
+	case *structpb.Value_StringValue:
+		return "string"
+	case *structpb.Value_StructValue:
+		return "object"
+	case *structpb.Value_ListValue:
+		return "array"
+	default:
+		return "null"
+	}
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// ListFlags returns the metadata of all the flags of the loaded environment configuration, sorted by key
+func (b *Engine) ListFlags() ([]*model.FlagMetadata, error) {
+	if b.getConfig() == nil {
+		logger.Info("Configuration not loaded. Loading it now")
+		err := b.Load()
+		if err != nil {
+			logger.Warning("Configuration could not be loaded.")
+			return nil, err
+		}
+	}
+
+	config := b.getConfig()
+
+	flags := map[string]*model.FlagMetadata{}
+	for _, c := range config.GetCampaigns() {
+		campaignContextKeys := []string{}
+		for _, vg := range c.GetVariationGroups() {
+			for _, k := range getTargetingContextKeys(vg.GetTargeting()) {
+				campaignContextKeys = appendUnique(campaignContextKeys, k)
+			}
+		}
+
+		// Keep track of the campaign metadata index for each flag of this campaign
+		campaignIndexes := map[string]int{}
+		for _, vg := range c.GetVariationGroups() {
+			for _, v := range vg.GetVariations() {
+				for key, value := range v.GetModifications().GetValue().GetFields() {
+					flag, ok := flags[key]
+					if !ok {
+						flag = &model.FlagMetadata{
+							Key:         key,
+							Types:       []string{},
+							Campaigns:   []model.FlagCampaignMetadata{},
+							ContextKeys: []string{},
+						}
+						flags[key] = flag
+					}
+
+					flag.Types = appendUnique(flag.Types, getValueType(value))
+					if v.GetReference() && flag.ReferenceValue == nil {
+						flag.ReferenceValue = value.AsInterface()
+					}
+
+					index, ok := campaignIndexes[key]
+					if !ok {
+						flag.Campaigns = append(flag.Campaigns, model.FlagCampaignMetadata{
+							ID:                c.GetId(),
+							Slug:              c.GetSlug().GetValue(),
+							Type:              c.GetType(),
+							VariationGroupIDs: []string{},
+						})
+						index = len(flag.Campaigns) - 1
+						campaignIndexes[key] = index
+						for _, k := range campaignContextKeys {
+							flag.ContextKeys = appendUnique(flag.ContextKeys, k)
+						}
+					}
+					flag.Campaigns[index].VariationGroupIDs = appendUnique(flag.Campaigns[index].VariationGroupIDs, vg.GetId())
+				}
+			}
+		}
+	}
+
+	result := make([]*model.FlagMetadata, 0, len(flags))
+	for _, flag := range flags {
+		sort.Strings(flag.Types)
+		sort.Strings(flag.ContextKeys)
+		result = append(result, flag)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package bucketing
+
+import (
+	"testing"
+
+	"github.com/flagship-io/flagship-proto/bucketing"
+	"github.com/flagship-io/flagship-proto/decision_response"
+	targetingTypes "github.com/flagship-io/flagship-proto/targeting"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestListFlags(t *testing.T) {
+	engine, _ := NewEngine(testEnvID, nil, PollingInterval(-1))
+
+	_, err := engine.ListFlags()
+	assert.NotNil(t, err)
+
+	config := &bucketing.Bucketing_BucketingResponse{
+		Campaigns: []*bucketing.Bucketing_BucketingCampaign{
+			engineMockConfig.Campaigns[0],
+			{
+				Id:   "test_cid_2",
+				Type: "toggle",
+				Slug: wrapperspb.String("slug"),
+				VariationGroups: []*bucketing.Bucketing_BucketingVariationGroups{{
+					Id: "test_vgid_2",
+					Targeting: &targetingTypes.Targeting{
+						TargetingGroups: []*targetingTypes.Targeting_TargetingGroup{{
+							Targetings: []*targetingTypes.Targeting_InnerTargeting{{
+								Operator: targetingTypes.Targeting_EQUALS,
+								Key:      wrapperspb.String("fs_all_users"),
+								Value:    structpb.NewStringValue(""),
+							}, {
+								Operator: targetingTypes.Targeting_EQUALS,
+								Key:      wrapperspb.String("country"),
+								Value:    structpb.NewStringValue("FR"),
+							}},
+						}},
+					},
+					Variations: []*decision_response.FullVariation{{
+						Id:        wrapperspb.String("ref"),
+						Reference: true,
+						Modifications: &decision_response.Modifications{
+							Type: decision_response.ModificationsType_FLAG,
+							Value: &structpb.Struct{
+								Fields: map[string]*structpb.Value{
+									"test":  structpb.NewStringValue("value"),
+									"color": structpb.NewStringValue("blue"),
+								},
+							},
+						},
+					}},
+				}},
+			},
+		},
+	}
+	engine.apiClient = NewAPIClientMock(testEnvID, config, 200)
+	_ = engine.Load()
+
+	flags, err := engine.ListFlags()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(flags))
+
+	assert.Equal(t, "color", flags[0].Key)
+	assert.Equal(t, []string{"string"}, flags[0].Types)
+	assert.Equal(t, "blue", flags[0].ReferenceValue)
+	assert.Equal(t, []string{"country"}, flags[0].ContextKeys)
+	assert.Equal(t, 1, len(flags[0].Campaigns))
+	assert.Equal(t, "test_cid_2", flags[0].Campaigns[0].ID)
+	assert.Equal(t, "slug", flags[0].Campaigns[0].Slug)
+	assert.Equal(t, "toggle", flags[0].Campaigns[0].Type)
+	assert.Equal(t, []string{"test_vgid_2"}, flags[0].Campaigns[0].VariationGroupIDs)
+
+	assert.Equal(t, "test", flags[1].Key)
+	assert.Equal(t, []string{"bool", "string"}, flags[1].Types)
+	assert.Equal(t, "value", flags[1].ReferenceValue)
+	assert.Equal(t, []string{"country", "test"}, flags[1].ContextKeys)
+	assert.Equal(t, 2, len(flags[1].Campaigns))
+	assert.Equal(t, "test_cid", flags[1].Campaigns[0].ID)
+	assert.Equal(t, []string{"test_vgid"}, flags[1].Campaigns[0].VariationGroupIDs)
+}
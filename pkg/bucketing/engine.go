@@ -1,6 +1,8 @@
 package bucketing
 
 import (
+	"crypto/ed25519"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
 	bucketingProto "github.com/flagship-io/flagship-proto/bucketing"
+	"google.golang.org/protobuf/proto"
 )
 
 var logger = logging.CreateLogger("Bucketing Engine")
@@ -22,14 +25,20 @@ type EngineOptions struct {
 
 // Engine represents a bucketing engine
 type Engine struct {
-	pollingInterval  time.Duration
-	config           *bucketingProto.Bucketing_BucketingResponse
-	apiClient        ConfigAPIInterface
-	apiClientOptions []func(*APIClient)
-	cacheManager     cache.Manager
-	envID            string
-	configMux        sync.RWMutex
-	ticker           *time.Ticker
+	pollingInterval        time.Duration
+	config                 *bucketingProto.Bucketing_BucketingResponse
+	apiClient              ConfigAPIInterface
+	apiClientOptions       []func(*APIClient)
+	cacheManager           cache.Manager
+	envID                  string
+	configMux              sync.RWMutex
+	ticker                 *time.Ticker
+	enableBucketAllocation bool
+	campaignTypes          map[string]bool
+	flagKeys               map[string]bool
+	signatureVerifier      SignatureVerifier
+	configVersion          int64
+	targetingContextKeys   map[string]bool
 }
 
 // PollingInterval sets the polling interval for the bucketing engine
@@ -46,6 +55,45 @@ func APIOptions(apiOptions ...func(*APIClient)) func(r *Engine) {
 	}
 }
 
+// EnableBucketAllocation enables the bucket allocation of the campaigns when computing decisions
+func EnableBucketAllocation(enabled bool) func(r *Engine) {
+	return func(r *Engine) {
+		r.enableBucketAllocation = enabled
+	}
+}
+
+// CampaignTypes restricts the decisions to the campaigns of the given types (ab, toggle, perso...)
+func CampaignTypes(campaignTypes ...string) func(r *Engine) {
+	return func(r *Engine) {
+		r.campaignTypes = map[string]bool{}
+		for _, t := range campaignTypes {
+			r.campaignTypes[strings.ToLower(t)] = true
+		}
+	}
+}
+
+// FlagKeys restricts the decisions to the campaigns that modify at least one of the given flag keys
+func FlagKeys(flagKeys ...string) func(r *Engine) {
+	return func(r *Engine) {
+		r.flagKeys = map[string]bool{}
+		for _, k := range flagKeys {
+			r.flagKeys[k] = true
+		}
+	}
+}
+
+// VerifySignature refuses bucketing configurations that are not correctly signed for the verifier
+func VerifySignature(verifier SignatureVerifier) func(r *Engine) {
+	return func(r *Engine) {
+		r.signatureVerifier = verifier
+	}
+}
+
+// PublicKey refuses bucketing configurations that are not signed with the private key matching the Ed25519 public key
+func PublicKey(publicKey ed25519.PublicKey) func(r *Engine) {
+	return VerifySignature(NewEd25519Verifier(publicKey))
+}
+
 // NewEngine creates a new engine for bucketing
 func NewEngine(envID string, cacheManager cache.Manager, params ...func(*Engine)) (*Engine, error) {
 	engine := &Engine{
@@ -59,7 +107,11 @@ func NewEngine(envID string, cacheManager cache.Manager, params ...func(*Engine)
 		param(engine)
 	}
 
-	engine.apiClient = NewAPIClient(envID, engine.apiClientOptions...)
+	apiClientOptions := append([]func(*APIClient){}, engine.apiClientOptions...)
+	if engine.signatureVerifier != nil {
+		apiClientOptions = append(apiClientOptions, SignatureVerification(engine.signatureVerifier))
+	}
+	engine.apiClient = NewAPIClient(envID, apiClientOptions...)
 
 	err := engine.Load()
 
@@ -103,11 +155,59 @@ func (b *Engine) Load() error {
 		return err
 	}
 
+	if b.config == nil || !proto.Equal(b.config, newConfig) {
+		b.configVersion++
+		b.targetingContextKeys = map[string]bool{}
+		for _, c := range newConfig.GetCampaigns() {
+			for _, vg := range c.GetVariationGroups() {
+				for _, k := range getTargetingContextKeys(vg.GetTargeting()) {
+					b.targetingContextKeys[k] = true
+				}
+			}
+		}
+	}
+
 	b.config = newConfig
 
 	return nil
 }
 
+// GetConfigVersion returns a version number incremented each time a different configuration is loaded
+func (b *Engine) GetConfigVersion() int64 {
+	b.configMux.RLock()
+	defer b.configMux.RUnlock()
+	return b.configVersion
+}
+
+// IsTargetingContextKey returns true if the context key is used by the targeting of a campaign of the loaded configuration
+func (b *Engine) IsTargetingContextKey(key string) bool {
+	b.configMux.RLock()
+	defer b.configMux.RUnlock()
+	return b.targetingContextKeys[key]
+}
+
+// isCampaignEvaluated checks that the campaign matches the campaign types and flag keys filters of the engine
+func (b *Engine) isCampaignEvaluated(campaign *bucketingProto.Bucketing_BucketingCampaign) bool {
+	if len(b.campaignTypes) > 0 && !b.campaignTypes[strings.ToLower(campaign.GetType())] {
+		return false
+	}
+
+	if len(b.flagKeys) == 0 {
+		return true
+	}
+
+	for _, vg := range campaign.GetVariationGroups() {
+		for _, v := range vg.GetVariations() {
+			for k := range v.GetModifications().GetValue().GetFields() {
+				if b.flagKeys[k] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func (b *Engine) getCampaignCache(visitorID string) cache.CampaignCacheMap {
 	var campaignsCache = make(map[string]*cache.CampaignCache)
 	if b.cacheManager != nil {
@@ -121,7 +221,15 @@ func (b *Engine) getCampaignCache(visitorID string) cache.CampaignCacheMap {
 
 // GetModifications gets modifications from Decision API
 func (b *Engine) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	return b.getModifications(visitorID, anonymousID, context, nil)
+}
+
+// GetModificationsWithConsent gets modifications like GetModifications. The visitor cache is not saved without consent
+func (b *Engine) GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	return b.getModifications(visitorID, anonymousID, context, &hasConsented)
+}
 
+func (b *Engine) getModifications(visitorID string, anonymousID *string, context model.Context, hasConsented *bool) (*model.APIClientResponse, error) {
 	if b.getConfig() == nil {
 		logger.Info("Configuration not loaded. Loading it now")
 		err := b.Load()
@@ -134,6 +242,7 @@ func (b *Engine) GetModifications(visitorID string, anonymousID *string, context
 	resp := &model.APIClientResponse{
 		VisitorID: visitorID,
 		Campaigns: []model.Campaign{},
+		Source:    model.SOURCE_BUCKETING,
 	}
 
 	if b.getConfig().Panic {
@@ -147,6 +256,9 @@ func (b *Engine) GetModifications(visitorID string, anonymousID *string, context
 
 	commonCampaigns := []*common.Campaign{}
 	for _, v := range config.Campaigns {
+		if !b.isCampaignEvaluated(v) {
+			continue
+		}
 		commonCampaigns = append(commonCampaigns, model.CampaignToCommonStruct(v))
 	}
 	anonymousIDString := ""
@@ -160,7 +272,14 @@ func (b *Engine) GetModifications(visitorID string, anonymousID *string, context
 		return resp, nil
 	}
 
-	enableBucketAllocation := false
+	// With experience continuity, the anonymous visitor assignments are reused for the authenticated visitor
+	useReconciliation := config.GetAccountSettings().GetEnabledXPC() && anonymousIDString != "" && anonymousIDString != visitorID
+	var anonymousCampaignsCache cache.CampaignCacheMap
+	if useReconciliation {
+		anonymousCampaignsCache = b.getCampaignCache(anonymousIDString)
+	}
+
+	enableBucketAllocation := b.enableBucketAllocation
 	decisionResponse, err := common.GetDecision(common.Visitor{
 		ID:          visitorID,
 		AnonymousID: anonymousIDString,
@@ -178,6 +297,9 @@ func (b *Engine) GetModifications(visitorID string, anonymousID *string, context
 		EnableBucketAllocation: &enableBucketAllocation,
 	}, common.DecisionHandlers{
 		GetCache: func(environmentID, id string) (*common.VisitorAssignments, error) {
+			if useReconciliation && id == anonymousIDString {
+				return anonymousCampaignsCache.ToCommonStruct(), nil
+			}
 			return campaignsCache.ToCommonStruct(), nil
 		},
 	})
@@ -219,7 +341,11 @@ func (b *Engine) GetModifications(visitorID string, anonymousID *string, context
 		}
 	}
 
-	if b.cacheManager != nil {
+	if useReconciliation {
+		reconcileCampaignsCache(campaignsCache, anonymousCampaignsCache, anonymousIDString)
+	}
+
+	if b.cacheManager != nil && (hasConsented == nil || *hasConsented) {
 		err := b.cacheManager.Set(visitorID, campaignsCache)
 		if err != nil {
 			logger.Warnf("Cache saving failed: %v", err)
@@ -63,3 +63,10 @@ func GetBucketingEngineMock(testEnvID string, cache cache.Manager) *Engine {
 	engine.cacheManager = cache
 	return engine
 }
+
+// SetMockConfig makes the engine load the given configuration
+func SetMockConfig(engine *Engine, config *bucketing.Bucketing_BucketingResponse) {
+	engine.configMux.Lock()
+	defer engine.configMux.Unlock()
+	engine.apiClient = NewAPIClientMock(engine.envID, config, 200)
+}
@@ -1,14 +1,20 @@
 package bucketing
 
 import (
+	"fmt"
 	"log"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
 	"github.com/flagship-io/flagship-proto/bucketing"
+	"github.com/flagship-io/flagship-proto/decision_response"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 var testVID = "test_vid"
@@ -178,3 +184,172 @@ func TestPollingPanic(t *testing.T) {
 	assert.Equal(t, 1, len(engine.getConfig().Campaigns))
 	assert.Equal(t, true, engine.getConfig().Panic)
 }
+
+func TestEngineOptions(t *testing.T) {
+	engine, _ := NewEngine(testEnvID, nil, PollingInterval(-1), EnableBucketAllocation(true), CampaignTypes("AB", "toggle"), FlagKeys("test"))
+
+	assert.True(t, engine.enableBucketAllocation)
+	assert.Equal(t, map[string]bool{"ab": true, "toggle": true}, engine.campaignTypes)
+	assert.Equal(t, map[string]bool{"test": true}, engine.flagKeys)
+}
+
+func TestGetModificationsFilters(t *testing.T) {
+	config := &bucketing.Bucketing_BucketingResponse{
+		Campaigns: []*bucketing.Bucketing_BucketingCampaign{
+			engineMockConfig.Campaigns[0],
+			{
+				Id:   "test_cid_toggle",
+				Type: "toggle",
+				VariationGroups: []*bucketing.Bucketing_BucketingVariationGroups{{
+					Id:        "test_vgid_toggle",
+					Targeting: engineMockConfig.Campaigns[0].VariationGroups[0].Targeting,
+					Variations: []*decision_response.FullVariation{{
+						Id:         wrapperspb.String("2"),
+						Allocation: 100,
+						Modifications: &decision_response.Modifications{
+							Type: decision_response.ModificationsType_FLAG,
+							Value: &structpb.Struct{
+								Fields: map[string]*structpb.Value{
+									"toggle": structpb.NewBoolValue(true),
+								},
+							},
+						},
+					}},
+				}},
+			},
+		},
+	}
+
+	engine, _ := NewEngine(testEnvID, nil, PollingInterval(-1))
+	engine.apiClient = NewAPIClientMock(testEnvID, config, 200)
+	_ = engine.Load()
+
+	modifs, err := engine.GetModifications(testVID, nil, map[string]interface{}{"test": true})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(modifs.Campaigns))
+
+	CampaignTypes("toggle")(engine)
+	modifs, err = engine.GetModifications(testVID, nil, map[string]interface{}{"test": true})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(modifs.Campaigns))
+	assert.Equal(t, "test_cid_toggle", modifs.Campaigns[0].ID)
+
+	CampaignTypes()(engine)
+	FlagKeys("test")(engine)
+	modifs, err = engine.GetModifications(testVID, nil, map[string]interface{}{"test": true})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(modifs.Campaigns))
+	assert.Equal(t, "test_cid", modifs.Campaigns[0].ID)
+
+	CampaignTypes("toggle")(engine)
+	modifs, err = engine.GetModifications(testVID, nil, map[string]interface{}{"test": true})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(modifs.Campaigns))
+}
+
+func TestConfigVersion(t *testing.T) {
+	engine := GetBucketingEngineMock(testEnvID, nil)
+	assert.Equal(t, int64(0), engine.GetConfigVersion())
+
+	_ = engine.Load()
+	assert.Equal(t, int64(1), engine.GetConfigVersion())
+	assert.True(t, engine.IsTargetingContextKey("test"))
+	assert.False(t, engine.IsTargetingContextKey("other"))
+
+	_ = engine.Load()
+	assert.Equal(t, int64(1), engine.GetConfigVersion())
+
+	SetMockConfig(engine, &bucketing.Bucketing_BucketingResponse{})
+	_ = engine.Load()
+	assert.Equal(t, int64(2), engine.GetConfigVersion())
+	assert.False(t, engine.IsTargetingContextKey("test"))
+}
+
+func TestGetModificationsConsent(t *testing.T) {
+	cacheCampaignsVisitors := map[string]map[string]*cache.CampaignCache{}
+	cacheManager, _ := cache.InitManager(cache.WithCustomOptions(cache.CustomOptions{
+		Getter: func(visitorID string) (map[string]*cache.CampaignCache, error) {
+			return cacheCampaignsVisitors[visitorID], nil
+		},
+		Setter: func(visitorID string, cache map[string]*cache.CampaignCache) error {
+			cacheCampaignsVisitors[visitorID] = cache
+			return nil
+		},
+	}))
+	engine := GetBucketingEngineMock(testEnvID, cacheManager)
+
+	// The cache is not saved without consent
+	modifs, err := engine.GetModificationsWithConsent(testVID, nil, model.Context{"test": true}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(modifs.Campaigns))
+	assert.Nil(t, cacheCampaignsVisitors[testVID])
+
+	_, err = engine.GetModificationsWithConsent(testVID, nil, model.Context{"test": true}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(cacheCampaignsVisitors[testVID]))
+}
+
+func TestGetModificationsReconciliation(t *testing.T) {
+	cacheCampaignsVisitors := map[string]map[string]*cache.CampaignCache{}
+	cacheManager, _ := cache.InitManager(cache.WithCustomOptions(cache.CustomOptions{
+		Getter: func(visitorID string) (map[string]*cache.CampaignCache, error) {
+			return cacheCampaignsVisitors[visitorID], nil
+		},
+		Setter: func(visitorID string, cache map[string]*cache.CampaignCache) error {
+			cacheCampaignsVisitors[visitorID] = cache
+			return nil
+		},
+	}))
+	engine := GetBucketingEngineMock(testEnvID, cacheManager)
+
+	config := proto.Clone(engineMockConfig).(*bucketing.Bucketing_BucketingResponse)
+	config.Campaigns[0].VariationGroups[0].Variations[1].Id = wrapperspb.String("2")
+	SetMockConfig(engine, config)
+	_ = engine.Load()
+
+	// The anonymous visitor has been assigned to the variation 2 of another campaign and of the test campaign
+	anonymousID := "anonymous_vid"
+	cacheCampaignsVisitors[anonymousID] = map[string]*cache.CampaignCache{
+		"test_cid": {
+			VariationGroupID: "test_vgid",
+			VariationID:      "2",
+			Activated:        true,
+		},
+		"other_cid": {
+			VariationGroupID: "other_vgid",
+			VariationID:      "other_vid",
+		},
+	}
+
+	// Without experience continuity, the anonymous assignments are not reused
+	assignedVariations := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		modifs, err := engine.GetModifications(fmt.Sprintf("vid_%d", i), &anonymousID, model.Context{"test": true})
+		assert.Nil(t, err)
+		assignedVariations[modifs.Campaigns[0].Variation.ID] = true
+	}
+	assert.True(t, assignedVariations["1"])
+	assert.Nil(t, cacheCampaignsVisitors["vid_0"]["other_cid"])
+
+	config.AccountSettings = &decision_response.AccountSettings{EnabledXPC: true}
+	SetMockConfig(engine, config)
+	_ = engine.Load()
+
+	for i := 0; i < 20; i++ {
+		visitorID := fmt.Sprintf("authenticated_vid_%d", i)
+		modifs, err := engine.GetModifications(visitorID, &anonymousID, model.Context{"test": true})
+		assert.Nil(t, err)
+		assert.Equal(t, "2", modifs.Campaigns[0].Variation.ID)
+
+		visitorCache := cacheCampaignsVisitors[visitorID]
+		assert.Equal(t, 2, len(visitorCache))
+		assert.Equal(t, "2", visitorCache["test_cid"].VariationID)
+		assert.False(t, visitorCache["test_cid"].Activated)
+		assert.Equal(t, "other_vid", visitorCache["other_cid"].VariationID)
+		assert.Equal(t, []string{anonymousID}, cache.CampaignCacheMap(visitorCache).GetAnonymousIDs())
+	}
+
+	// The anonymous cache is left untouched
+	assert.Equal(t, 2, len(cacheCampaignsVisitors[anonymousID]))
+	assert.True(t, cacheCampaignsVisitors[anonymousID]["test_cid"].Activated)
+}
@@ -0,0 +1,23 @@
+package bucketing
+
+import "github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
+
+// reconcileCampaignsCache merges the cached assignments of the anonymous visitor into the authenticated visitor cache,
+// and links the authenticated visitor assignments to the anonymous ID. Assignments of the authenticated visitor take precedence
+func reconcileCampaignsCache(campaignsCache cache.CampaignCacheMap, anonymousCampaignsCache cache.CampaignCacheMap, anonymousID string) {
+	for id, c := range anonymousCampaignsCache {
+		if _, ok := campaignsCache[id]; ok || c == nil {
+			continue
+		}
+		merged := *c
+		// The campaign has not been activated for the authenticated visitor yet
+		merged.Activated = false
+		campaignsCache[id] = &merged
+	}
+
+	for _, c := range campaignsCache {
+		if c != nil && c.AnonymousID == "" {
+			c.AnonymousID = anonymousID
+		}
+	}
+}
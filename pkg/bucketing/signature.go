@@ -0,0 +1,74 @@
+package bucketing
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+const defaultSignatureHeader = "x-fs-signature"
+
+// SignatureVerifier verifies the signature of a raw bucketing configuration
+type SignatureVerifier interface {
+	Verify(body []byte, signature []byte) error
+}
+
+// Ed25519Verifier verifies Ed25519 signatures with a public key
+type Ed25519Verifier struct {
+	publicKey ed25519.PublicKey
+}
+
+// NewEd25519Verifier creates a signature verifier from an Ed25519 public key
+func NewEd25519Verifier(publicKey ed25519.PublicKey) *Ed25519Verifier {
+	return &Ed25519Verifier{
+		publicKey: publicKey,
+	}
+}
+
+// Verify checks that the signature matches the body
+func (v *Ed25519Verifier) Verify(body []byte, signature []byte) error {
+	if len(v.publicKey) != ed25519.PublicKeySize {
+		return errors.New("Invalid Ed25519 public key size")
+	}
+	if !ed25519.Verify(v.publicKey, body, signature) {
+		return errors.New("Invalid Ed25519 signature")
+	}
+	return nil
+}
+
+// HMACVerifier verifies HMAC-SHA256 signatures with a shared secret
+type HMACVerifier struct {
+	secret []byte
+}
+
+// NewHMACVerifier creates a signature verifier from an HMAC-SHA256 shared secret
+func NewHMACVerifier(secret []byte) *HMACVerifier {
+	return &HMACVerifier{
+		secret: secret,
+	}
+}
+
+// Verify checks that the signature matches the body
+func (v *HMACVerifier) Verify(body []byte, signature []byte) error {
+	if len(v.secret) == 0 {
+		return errors.New("HMAC secret should not be empty")
+	}
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return errors.New("Invalid HMAC signature")
+	}
+	return nil
+}
+
+// decodeSignature decodes a base64 encoded signature, ignoring surrounding whitespaces
+func decodeSignature(signature string) ([]byte, error) {
+	signature = strings.TrimSpace(signature)
+	if signature == "" {
+		return nil, errors.New("Bucketing configuration is not signed")
+	}
+	return base64.StdEncoding.DecodeString(signature)
+}
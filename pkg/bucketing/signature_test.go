@@ -0,0 +1,152 @@
+package bucketing
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var signedConfig = []byte(`{"campaigns":[{"id":"test_cid","type":"ab"}]}`)
+var tamperedConfig = []byte(`{"campaigns":[{"id":"tampered_cid","type":"ab"}]}`)
+
+func hmacSign(secret []byte, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func createSignedServer(body []byte, headerSignature string, fileSignature string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + testEnvID + "/bucketing.json":
+			if headerSignature != "" {
+				w.Header().Set(defaultSignatureHeader, headerSignature)
+			}
+			_, _ = w.Write(body)
+		case "/" + testEnvID + "/bucketing.json.sig":
+			if fileSignature == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(fileSignature))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestEd25519Verifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+
+	verifier := NewEd25519Verifier(publicKey)
+	assert.Nil(t, verifier.Verify(signedConfig, ed25519.Sign(privateKey, signedConfig)))
+	assert.NotNil(t, verifier.Verify(tamperedConfig, ed25519.Sign(privateKey, signedConfig)))
+	assert.NotNil(t, verifier.Verify(signedConfig, []byte("wrong")))
+
+	otherPublicKey, _, _ := ed25519.GenerateKey(nil)
+	assert.NotNil(t, NewEd25519Verifier(otherPublicKey).Verify(signedConfig, ed25519.Sign(privateKey, signedConfig)))
+	assert.NotNil(t, NewEd25519Verifier(nil).Verify(signedConfig, ed25519.Sign(privateKey, signedConfig)))
+}
+
+func TestHMACVerifier(t *testing.T) {
+	secret := []byte("test_secret")
+
+	verifier := NewHMACVerifier(secret)
+	assert.Nil(t, verifier.Verify(signedConfig, hmacSign(secret, signedConfig)))
+	assert.NotNil(t, verifier.Verify(tamperedConfig, hmacSign(secret, signedConfig)))
+	assert.NotNil(t, verifier.Verify(signedConfig, hmacSign([]byte("other_secret"), signedConfig)))
+	assert.NotNil(t, NewHMACVerifier(nil).Verify(signedConfig, hmacSign(nil, signedConfig)))
+}
+
+func TestGetConfigurationSignatureHeader(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, signedConfig))
+
+	server := createSignedServer(signedConfig, signature, "")
+	defer server.Close()
+
+	client := NewAPIClient(testEnvID, APIUrl(server.URL), SignatureVerification(NewEd25519Verifier(publicKey)))
+	conf, err := client.GetConfiguration()
+	assert.Nil(t, err)
+	assert.Equal(t, "test_cid", conf.Campaigns[0].Id)
+
+	// Unsigned configuration
+	unsignedServer := createSignedServer(signedConfig, "", "")
+	defer unsignedServer.Close()
+
+	client = NewAPIClient(testEnvID, APIUrl(unsignedServer.URL), SignatureVerification(NewEd25519Verifier(publicKey)))
+	conf, err = client.GetConfiguration()
+	assert.NotNil(t, err)
+	assert.Nil(t, conf)
+
+	// Tampered configuration
+	tamperedServer := createSignedServer(tamperedConfig, signature, "")
+	defer tamperedServer.Close()
+
+	client = NewAPIClient(testEnvID, APIUrl(tamperedServer.URL), SignatureVerification(NewEd25519Verifier(publicKey)))
+	conf, err = client.GetConfiguration()
+	assert.NotNil(t, err)
+	assert.Nil(t, conf)
+
+	// Custom header with HMAC
+	secret := []byte("test_secret")
+	hmacServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-custom-signature", base64.StdEncoding.EncodeToString(hmacSign(secret, signedConfig)))
+		_, _ = w.Write(signedConfig)
+	}))
+	defer hmacServer.Close()
+
+	client = NewAPIClient(testEnvID, APIUrl(hmacServer.URL), SignatureVerification(NewHMACVerifier(secret)), SignatureHeader("x-custom-signature"))
+	conf, err = client.GetConfiguration()
+	assert.Nil(t, err)
+	assert.Equal(t, "test_cid", conf.Campaigns[0].Id)
+}
+
+func TestGetConfigurationSignatureFile(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, signedConfig))
+	signatureFile := "/" + testEnvID + "/bucketing.json.sig"
+
+	server := createSignedServer(signedConfig, "", signature+"\n")
+	defer server.Close()
+
+	client := NewAPIClient(testEnvID, APIUrl(server.URL), SignatureVerification(NewEd25519Verifier(publicKey)), SignatureFile(signatureFile))
+	conf, err := client.GetConfiguration()
+	assert.Nil(t, err)
+	assert.Equal(t, "test_cid", conf.Campaigns[0].Id)
+
+	// Missing signature file
+	missingServer := createSignedServer(signedConfig, signature, "")
+	defer missingServer.Close()
+
+	client = NewAPIClient(testEnvID, APIUrl(missingServer.URL), SignatureVerification(NewEd25519Verifier(publicKey)), SignatureFile(signatureFile))
+	_, err = client.GetConfiguration()
+	assert.NotNil(t, err)
+}
+
+func TestEngineKeepsConfigOnInvalidSignature(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, signedConfig))
+
+	server := createSignedServer(signedConfig, signature, "")
+	defer server.Close()
+
+	engine, err := NewEngine(testEnvID, nil, PollingInterval(-1), APIOptions(APIUrl(server.URL)), PublicKey(publicKey))
+	assert.Nil(t, err)
+	assert.Equal(t, "test_cid", engine.getConfig().Campaigns[0].Id)
+
+	tamperedServer := createSignedServer(tamperedConfig, signature, "")
+	defer tamperedServer.Close()
+
+	engine.apiClient = NewAPIClient(testEnvID, APIUrl(tamperedServer.URL), SignatureVerification(engine.signatureVerifier))
+	err = engine.Load()
+	assert.NotNil(t, err)
+	assert.Equal(t, "test_cid", engine.getConfig().Campaigns[0].Id)
+}
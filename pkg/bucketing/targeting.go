@@ -0,0 +1,26 @@
+package bucketing
+
+import (
+	targetingProto "github.com/flagship-io/flagship-proto/targeting"
+)
+
+// reservedTargetingKeys are targeting keys that do not refer to the visitor context
+var reservedTargetingKeys = map[string]bool{
+	"fs_all_users": true,
+	"fs_users":     true,
+}
+
+// getTargetingContextKeys returns the visitor context keys used by the targeting
+func getTargetingContextKeys(targeting *targetingProto.Targeting) []string {
+	keys := []string{}
+	for _, tg := range targeting.GetTargetingGroups() {
+		for _, t := range tg.GetTargetings() {
+			key := t.GetKey().GetValue()
+			if key == "" || reservedTargetingKeys[key] {
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"errors"
+	"sort"
 	"time"
 
 	common "github.com/flagship-io/flagship-common"
@@ -27,6 +29,13 @@ type CampaignCache struct {
 	VariationID      string
 	Activated        bool
 	FlagKeys         []string
+	// AnonymousID is the anonymous ID of the visitor when the assignment has been made with experience continuity
+	AnonymousID string `json:",omitempty"`
+	// The following fields are only saved by the Decision API client, to serve the decision again when the API is unreachable
+	IsReference      bool                   `json:",omitempty"`
+	ModificationType string                 `json:",omitempty"`
+	FlagValues       map[string]interface{} `json:",omitempty"`
+	UpdatedAt        *time.Time             `json:",omitempty"`
 }
 
 func (ccmap CampaignCacheMap) ToCommonStruct() *common.VisitorAssignments {
@@ -43,6 +52,23 @@ func (ccmap CampaignCacheMap) ToCommonStruct() *common.VisitorAssignments {
 	}
 }
 
+// GetAnonymousIDs returns the sorted anonymous IDs linked to the cached assignments
+func (ccmap CampaignCacheMap) GetAnonymousIDs() []string {
+	ids := map[string]bool{}
+	for _, v := range ccmap {
+		if v != nil && v.AnonymousID != "" {
+			ids[v.AnonymousID] = true
+		}
+	}
+
+	anonymousIDs := []string{}
+	for id := range ids {
+		anonymousIDs = append(anonymousIDs, id)
+	}
+	sort.Strings(anonymousIDs)
+	return anonymousIDs
+}
+
 // Options expresses all the possible options for cache manager
 type Options struct {
 	cacheType ManagerType
@@ -60,8 +86,22 @@ type Manager interface {
 	Get(visitorID string) (map[string]*CampaignCache, error)
 }
 
+// DeleterInterface is implemented by the cache managers that can delete the cache of a visitor
+type DeleterInterface interface {
+	Delete(visitorID string) error
+}
+
 var cacheLogger = logging.CreateLogger("cache")
 
+// Delete deletes the cache of the visitor, if the cache manager can delete it
+func Delete(manager Manager, visitorID string) error {
+	deleter, ok := manager.(DeleterInterface)
+	if !ok {
+		return errors.New("Cache manager does not handle deletion")
+	}
+	return deleter.Delete(visitorID)
+}
+
 // InitManager initialize the manager with a type and options
 func InitManager(optionsFunc ...OptionBuilder) (manager Manager, err error) {
 	options := &Options{}
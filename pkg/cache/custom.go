@@ -2,18 +2,46 @@ package cache
 
 import (
 	"errors"
+	"time"
 )
 
 // CustomManager represents the local db manager object
 type CustomManager struct {
-	getter func(visitorID string) (map[string]*CampaignCache, error)
-	setter func(visitorID string, campaignCache map[string]*CampaignCache) error
+	getter  func(visitorID string) (map[string]*CampaignCache, error)
+	setter  func(visitorID string, campaignCache map[string]*CampaignCache) error
+	deleter func(visitorID string) error
+
+	sessionGetter func(visitorID string) (*SessionCache, error)
+	sessionSetter func(visitorID string, session *SessionCache, timeout time.Duration) error
+}
+
+// customDeleter is a custom manager with a deleter, that can delete the cache of a visitor
+type customDeleter struct {
+	*CustomManager
+}
+
+// customSessionManager is a custom manager with a session getter and setter, that can save the sessions of the visitors
+type customSessionManager struct {
+	*CustomManager
+}
+
+// customDeleterSessionManager is a custom manager with a deleter, a session getter and a session setter
+type customDeleterSessionManager struct {
+	*CustomManager
+	customDeleter
+	customSessionManager
 }
 
 // CustomOptions are the options necessary to make the local cache manager work
 type CustomOptions struct {
 	Getter func(visitorID string) (map[string]*CampaignCache, error)
 	Setter func(visitorID string, campaignCache map[string]*CampaignCache) error
+	// Deleter is optional, and required to delete the cache of a visitor
+	Deleter func(visitorID string) error
+	// SessionGetter and SessionSetter are optional, and both required to save the sessions of the visitors.
+	// The session can be removed after an inactivity longer than the timeout
+	SessionGetter func(visitorID string) (*SessionCache, error)
+	SessionSetter func(visitorID string, session *SessionCache, timeout time.Duration) error
 }
 
 // WithCustomOptions configures custom manager options
@@ -24,8 +52,8 @@ func WithCustomOptions(customOptions CustomOptions) func(options *Options) {
 	}
 }
 
-func initCustomManager(customOptions CustomOptions) (m *CustomManager, err error) {
-	m = &CustomManager{}
+func initCustomManager(customOptions CustomOptions) (manager Manager, err error) {
+	m := &CustomManager{}
 	if customOptions.Getter == nil {
 		err = errors.New("Missing getter function")
 	}
@@ -36,8 +64,25 @@ func initCustomManager(customOptions CustomOptions) (m *CustomManager, err error
 
 	m.getter = customOptions.Getter
 	m.setter = customOptions.Setter
+	m.deleter = customOptions.Deleter
+	m.sessionGetter = customOptions.SessionGetter
+	m.sessionSetter = customOptions.SessionSetter
 
-	return m, err
+	// The manager only exposes deletion and sessions when the optional functions are defined
+	hasDeleter := m.deleter != nil
+	hasSessions := m.sessionGetter != nil && m.sessionSetter != nil
+	switch {
+	case hasDeleter && hasSessions:
+		manager = &customDeleterSessionManager{m, customDeleter{m}, customSessionManager{m}}
+	case hasDeleter:
+		manager = &customDeleter{m}
+	case hasSessions:
+		manager = &customSessionManager{m}
+	default:
+		manager = m
+	}
+
+	return manager, err
 }
 
 // Set saves the campaigns in cache for this visitor
@@ -63,3 +108,18 @@ func (m *CustomManager) Get(visitorID string) (cache map[string]*CampaignCache,
 	}
 	return cache, err
 }
+
+// Delete deletes the campaigns in cache for this visitor
+func (m *customDeleter) Delete(visitorID string) error {
+	return m.deleter(visitorID)
+}
+
+// SetSession saves the session in cache for this visitor
+func (m *customSessionManager) SetSession(visitorID string, session *SessionCache, timeout time.Duration) error {
+	return m.sessionSetter(visitorID, session, timeout)
+}
+
+// GetSession returns the session in cache for this visitor
+func (m *customSessionManager) GetSession(visitorID string) (*SessionCache, error) {
+	return m.sessionGetter(visitorID)
+}
@@ -55,4 +55,34 @@ func TestCustomCache(t *testing.T) {
 	r, err := m.Get("test")
 	assert.Equal(t, nil, err)
 	assert.NotEqual(t, nil, r["testC"])
+
+	// The deleter is optional
+	_, ok := m.(DeleterInterface)
+	assert.False(t, ok)
+	err = Delete(m, "test")
+	assert.Equal(t, "Cache manager does not handle deletion", err.Error())
+
+	m, _ = initCustomManager(CustomOptions{
+		Getter: get,
+		Setter: set,
+		Deleter: func(visitorID string) error {
+			delete(cacheCampaignsVisitors, visitorID)
+			return nil
+		},
+	})
+	err = Delete(m, "test")
+	assert.Equal(t, nil, err)
+
+	_, err = m.Get("test")
+	assert.NotEqual(t, nil, err)
+}
+
+type getSetManager struct{}
+
+func (getSetManager) Set(visitorID string, campaignInfos map[string]*CampaignCache) error { return nil }
+func (getSetManager) Get(visitorID string) (map[string]*CampaignCache, error)             { return nil, nil }
+
+func TestDeleteNotHandled(t *testing.T) {
+	err := Delete(getSetManager{}, "test")
+	assert.NotEqual(t, nil, err)
 }
@@ -3,6 +3,7 @@ package cache
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	"git.mills.io/prologic/bitcask"
 )
@@ -43,6 +44,9 @@ func (m *LocalDBManager) Set(visitorID string, campaignCache map[string]*Campaig
 	if m.db == nil {
 		return errors.New("Cache db manager not initialized")
 	}
+	if err := checkVisitorKey(visitorID); err != nil {
+		return err
+	}
 
 	cache, err := json.Marshal(campaignCache)
 
@@ -58,6 +62,9 @@ func (m *LocalDBManager) Get(visitorID string) (map[string]*CampaignCache, error
 	if m.db == nil {
 		return nil, errors.New("Cache db manager not initialized")
 	}
+	if err := checkVisitorKey(visitorID); err != nil {
+		return nil, err
+	}
 
 	data, err := m.db.Get([]byte(visitorID))
 
@@ -75,6 +82,57 @@ func (m *LocalDBManager) Get(visitorID string) (map[string]*CampaignCache, error
 	return campaignCache, nil
 }
 
+// Delete deletes the campaigns and the session in cache for this visitor
+func (m *LocalDBManager) Delete(visitorID string) error {
+	if m.db == nil {
+		return errors.New("Cache db manager not initialized")
+	}
+	if err := checkVisitorKey(visitorID); err != nil {
+		return err
+	}
+
+	err := m.db.Delete([]byte(visitorID))
+	if err != nil {
+		return err
+	}
+	return m.db.Delete([]byte(getSessionKey(visitorID)))
+}
+
+// SetSession saves the session in cache for this visitor, until it expires after the timeout
+func (m *LocalDBManager) SetSession(visitorID string, session *SessionCache, timeout time.Duration) error {
+	if m.db == nil {
+		return errors.New("Cache db manager not initialized")
+	}
+
+	data, err := json.Marshal(session)
+	if err == nil {
+		err = m.db.PutWithTTL([]byte(getSessionKey(visitorID)), data, timeout)
+	}
+	return err
+}
+
+// GetSession returns the session in cache for this visitor
+func (m *LocalDBManager) GetSession(visitorID string) (*SessionCache, error) {
+	if m.db == nil {
+		return nil, errors.New("Cache db manager not initialized")
+	}
+
+	data, err := m.db.Get([]byte(getSessionKey(visitorID)))
+	if err == bitcask.ErrKeyNotFound || err == bitcask.ErrKeyExpired {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session := &SessionCache{}
+	err = json.Unmarshal(data, session)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
 // Dispose frees IO resources
 func (m *LocalDBManager) Dispose() error {
 	if m.db == nil {
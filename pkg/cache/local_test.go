@@ -44,6 +44,15 @@ func TestLocalCache(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.NotEqual(t, nil, r["testC"])
 
+	err = notInitialized.Delete("test")
+	assert.Equal(t, "Cache db manager not initialized", err.Error())
+
+	err = Delete(m, "test")
+	assert.Equal(t, nil, err)
+
+	_, err = m.Get("test")
+	assert.NotEqual(t, nil, err)
+
 	err = m.Dispose()
 	assert.Equal(t, nil, err)
 
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
 	"github.com/go-redis/redis/v8"
@@ -61,6 +62,9 @@ func (m *RedisManager) Set(visitorID string, campaignCache map[string]*CampaignC
 	if m.client == nil {
 		return errors.New("Redis cache manager not initialized")
 	}
+	if err := checkVisitorKey(visitorID); err != nil {
+		return err
+	}
 
 	data, err := json.Marshal(campaignCache)
 	if err != nil {
@@ -79,6 +83,9 @@ func (m *RedisManager) Get(visitorID string) (cache map[string]*CampaignCache, e
 	if m.client == nil {
 		return nil, errors.New("Redis cache manager not initialized")
 	}
+	if err := checkVisitorKey(visitorID); err != nil {
+		return nil, err
+	}
 
 	redisLogger.Info("Getting visitor cache")
 	cmd := m.client.Get(ctx, visitorID)
@@ -93,3 +100,51 @@ func (m *RedisManager) Get(visitorID string) (cache map[string]*CampaignCache, e
 
 	return cache, err
 }
+
+// Delete deletes the campaigns and the session in cache for this visitor
+func (m *RedisManager) Delete(visitorID string) error {
+	if m.client == nil {
+		return errors.New("Redis cache manager not initialized")
+	}
+	if err := checkVisitorKey(visitorID); err != nil {
+		return err
+	}
+
+	redisLogger.Info("Deleting visitor cache")
+	return m.client.Del(ctx, visitorID, getSessionKey(visitorID)).Err()
+}
+
+// SetSession saves the session in cache for this visitor, until it expires after the timeout
+func (m *RedisManager) SetSession(visitorID string, session *SessionCache, timeout time.Duration) error {
+	if m.client == nil {
+		return errors.New("Redis cache manager not initialized")
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	redisLogger.Info("Setting visitor session")
+	return m.client.Set(ctx, getSessionKey(visitorID), string(data), timeout).Err()
+}
+
+// GetSession returns the session in cache for this visitor
+func (m *RedisManager) GetSession(visitorID string) (*SessionCache, error) {
+	if m.client == nil {
+		return nil, errors.New("Redis cache manager not initialized")
+	}
+
+	redisLogger.Info("Getting visitor session")
+	data, err := m.client.Get(ctx, getSessionKey(visitorID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session := &SessionCache{}
+	err = json.Unmarshal(data, session)
+	return session, err
+}
@@ -53,4 +53,13 @@ func TestRedisCache(t *testing.T) {
 	r, err = m.Get("test")
 	assert.Equal(t, nil, err)
 	assert.NotEqual(t, nil, r["testC"])
+
+	err = notInitialized.Delete("test")
+	assert.Equal(t, "Redis cache manager not initialized", err.Error())
+
+	err = Delete(m, "test")
+	assert.Equal(t, nil, err)
+
+	_, err = m.Get("test")
+	assert.NotEqual(t, nil, err)
 }
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SESSION_KEY_PREFIX prefixes the visitor ID in the keys of the visitor sessions, to store them next to the campaigns.
+// The prefix is reserved: the local and redis cache managers refuse the visitor IDs starting with it
+const SESSION_KEY_PREFIX = "fs_session:"
+
+// SessionCache expresses the session object to be saved for a visitor
+type SessionCache struct {
+	// SessionNumber is the number of sessions of the visitor, including the current one
+	SessionNumber int64
+	// SessionTimestamp is the start time of the current session, as a Unix timestamp in seconds
+	SessionTimestamp int64
+	LastActivity     time.Time
+}
+
+// SessionManagerInterface is implemented by the cache managers that can save the sessions of the visitors
+type SessionManagerInterface interface {
+	// GetSession returns the session of the visitor, or nil if the visitor has no session
+	GetSession(visitorID string) (*SessionCache, error)
+	// SetSession saves the session of the visitor. The session can be removed after an inactivity longer than the timeout
+	SetSession(visitorID string, session *SessionCache, timeout time.Duration) error
+}
+
+// GetSession returns the session of the visitor, if the cache manager can save sessions
+func GetSession(manager Manager, visitorID string) (*SessionCache, error) {
+	sessionManager, ok := manager.(SessionManagerInterface)
+	if !ok {
+		return nil, errors.New("Cache manager does not handle sessions")
+	}
+	return sessionManager.GetSession(visitorID)
+}
+
+// SetSession saves the session of the visitor, if the cache manager can save sessions
+func SetSession(manager Manager, visitorID string, session *SessionCache, timeout time.Duration) error {
+	sessionManager, ok := manager.(SessionManagerInterface)
+	if !ok {
+		return errors.New("Cache manager does not handle sessions")
+	}
+	return sessionManager.SetSession(visitorID, session, timeout)
+}
+
+// HandlesSessions returns true if the cache manager can save the sessions of the visitors
+func HandlesSessions(manager Manager) bool {
+	_, ok := manager.(SessionManagerInterface)
+	return ok
+}
+
+func getSessionKey(visitorID string) string {
+	return SESSION_KEY_PREFIX + visitorID
+}
+
+// checkVisitorKey returns an error if the visitor ID would reach the keys of the sessions
+func checkVisitorKey(visitorID string) error {
+	if strings.HasPrefix(visitorID, SESSION_KEY_PREFIX) {
+		return fmt.Errorf("Visitor ID cannot start with the reserved prefix %s", SESSION_KEY_PREFIX)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSessionManager(t *testing.T, m Manager) {
+	session, err := GetSession(m, "test")
+	assert.Nil(t, err)
+	assert.Nil(t, session)
+
+	lastActivity := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	err = SetSession(m, "test", &SessionCache{SessionNumber: 2, SessionTimestamp: 1672567200, LastActivity: lastActivity}, time.Minute)
+	assert.Nil(t, err)
+
+	session, err = GetSession(m, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), session.SessionNumber)
+	assert.Equal(t, int64(1672567200), session.SessionTimestamp)
+	assert.True(t, lastActivity.Equal(session.LastActivity))
+
+	// The session is stored next to the campaigns of the visitor
+	_, err = m.Get("test")
+	assert.NotNil(t, err)
+
+	err = m.Set("test", map[string]*CampaignCache{"testC": {VariationGroupID: "vgID"}})
+	assert.Nil(t, err)
+	assert.Nil(t, Delete(m, "test"))
+
+	session, err = GetSession(m, "test")
+	assert.Nil(t, err)
+	assert.Nil(t, session)
+}
+
+// testReservedSessionKeys checks that the visitor IDs cannot reach the keys of the sessions stored next to the campaigns
+func testReservedSessionKeys(t *testing.T, m Manager) {
+	assert.Nil(t, SetSession(m, "victim", &SessionCache{SessionNumber: 3}, time.Minute))
+	assert.NotNil(t, m.Set(SESSION_KEY_PREFIX+"victim", map[string]*CampaignCache{}))
+	_, err := m.Get(SESSION_KEY_PREFIX + "victim")
+	assert.NotNil(t, err)
+	assert.NotNil(t, Delete(m, SESSION_KEY_PREFIX+"victim"))
+	session, err := GetSession(m, "victim")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), session.SessionNumber)
+}
+
+func TestLocalSession(t *testing.T) {
+	testFolder := "test_session"
+	defer os.RemoveAll(testFolder)
+
+	notInitialized := &LocalDBManager{}
+	_, err := notInitialized.GetSession("test")
+	assert.Equal(t, "Cache db manager not initialized", err.Error())
+	err = notInitialized.SetSession("test", &SessionCache{}, time.Minute)
+	assert.Equal(t, "Cache db manager not initialized", err.Error())
+
+	m, err := initLocalDBManager(LocalOptions{DbPath: testFolder})
+	assert.Nil(t, err)
+	defer m.Dispose()
+
+	testSessionManager(t, m)
+	testReservedSessionKeys(t, m)
+
+	// The session expires after the timeout
+	assert.Nil(t, SetSession(m, "expiring", &SessionCache{SessionNumber: 1}, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	session, err := GetSession(m, "expiring")
+	assert.Nil(t, err)
+	assert.Nil(t, session)
+}
+
+func TestRedisSession(t *testing.T) {
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	notInitialized := &RedisManager{}
+	_, err = notInitialized.GetSession("test")
+	assert.Equal(t, "Redis cache manager not initialized", err.Error())
+	err = notInitialized.SetSession("test", &SessionCache{}, time.Minute)
+	assert.Equal(t, "Redis cache manager not initialized", err.Error())
+
+	m, err := initRedisManager(RedisOptions{Host: s.Addr()})
+	assert.Nil(t, err)
+
+	testSessionManager(t, m)
+	assert.False(t, s.Exists(SESSION_KEY_PREFIX+"test"))
+	testReservedSessionKeys(t, m)
+
+	// The session expires after the timeout
+	assert.Nil(t, SetSession(m, "expiring", &SessionCache{SessionNumber: 1}, time.Minute))
+	assert.Equal(t, time.Minute, s.TTL(SESSION_KEY_PREFIX+"expiring"))
+	s.FastForward(2 * time.Minute)
+	session, err := GetSession(m, "expiring")
+	assert.Nil(t, err)
+	assert.Nil(t, session)
+}
+
+func TestCustomSession(t *testing.T) {
+	cacheCampaignsVisitors := map[string]map[string]*CampaignCache{}
+	sessions := map[string]*SessionCache{}
+	options := CustomOptions{
+		Getter: func(visitorID string) (map[string]*CampaignCache, error) {
+			return cacheCampaignsVisitors[visitorID], nil
+		},
+		Setter: func(visitorID string, cache map[string]*CampaignCache) error {
+			cacheCampaignsVisitors[visitorID] = cache
+			return nil
+		},
+	}
+
+	// The sessions are handled only when both the session getter and setter are defined
+	m, _ := initCustomManager(options)
+	assert.False(t, HandlesSessions(m))
+	_, err := GetSession(m, "test")
+	assert.Equal(t, "Cache manager does not handle sessions", err.Error())
+
+	options.SessionGetter = func(visitorID string) (*SessionCache, error) {
+		return sessions[visitorID], nil
+	}
+	m, _ = initCustomManager(options)
+	assert.False(t, HandlesSessions(m))
+	err = SetSession(m, "test", &SessionCache{}, time.Minute)
+	assert.Equal(t, "Cache manager does not handle sessions", err.Error())
+
+	var sessionTimeout time.Duration
+	options.SessionSetter = func(visitorID string, session *SessionCache, timeout time.Duration) error {
+		sessions[visitorID] = session
+		sessionTimeout = timeout
+		return nil
+	}
+	m, _ = initCustomManager(options)
+	assert.True(t, HandlesSessions(m))
+	_, ok := m.(DeleterInterface)
+	assert.False(t, ok)
+
+	options.Deleter = func(visitorID string) error {
+		delete(cacheCampaignsVisitors, visitorID)
+		delete(sessions, visitorID)
+		return nil
+	}
+	m, _ = initCustomManager(options)
+
+	testSessionManager(t, m)
+	assert.Equal(t, time.Minute, sessionTimeout)
+}
+
+func TestSessionNotHandled(t *testing.T) {
+	_, err := GetSession(getSetManager{}, "test")
+	assert.NotNil(t, err)
+
+	err = SetSession(getSetManager{}, "test", &SessionCache{}, time.Minute)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
+)
+
+const defaultBulkConcurrency = 10
+
+// VisitorRequest represents a visitor whose decision is requested in bulk
+type VisitorRequest struct {
+	ID          string
+	AnonymousID *string
+	Context     model.Context
+}
+
+// Result represents the decision of a visitor requested in bulk
+type Result struct {
+	// Index is the index of the visitor in the requests slice, as results are not sent in order
+	Index     int
+	VisitorID string
+	Response  *model.APIClientResponse
+	Flags     map[string]model.FlagInfos
+	Err       error
+}
+
+// getBulkConcurrency returns the number of decisions computed in parallel by GetDecisions
+func (c *Client) getBulkConcurrency() int {
+	if c.bulkConcurrency > 0 {
+		return c.bulkConcurrency
+	}
+	if c.decisionMode == Bucketing {
+		return runtime.NumCPU()
+	}
+	return defaultBulkConcurrency
+}
+
+// GetDecisions computes the decisions of many visitors and sends them to the returned channel as soon as they are ready.
+// Decisions are computed by a bounded number of workers, which wait for the results to be read before computing the next ones.
+// The channel is closed once all the results are sent, or when the context is done, in which case the remaining visitors are skipped.
+// No activation nor context event is sent for the visitors
+func (c *Client) GetDecisions(ctx context.Context, requests []VisitorRequest) <-chan Result {
+	results := make(chan Result)
+	jobs := make(chan int)
+
+	go func() {
+		defer close(jobs)
+		for i := range requests {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < c.getBulkConcurrency(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case results <- c.getDecision(i, requests[i]):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// getDecision computes the decision of a single visitor requested in bulk
+func (c *Client) getDecision(index int, request VisitorRequest) (result Result) {
+	result = Result{
+		Index:     index,
+		VisitorID: request.ID,
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result.Err = utils.HandleRecovered(r, clientLogger)
+		}
+	}()
+
+	if request.ID == "" {
+		result.Err = errors.New("Visitor ID should not be empty")
+		return result
+	}
+
+	errs := request.Context.Validate()
+	if len(errs) > 0 {
+		errorStrings := []string{}
+		for _, e := range errs {
+			errorStrings = append(errorStrings, e.Error())
+		}
+		result.Err = fmt.Errorf("Invalid context : %s", strings.Join(errorStrings, ", "))
+		return result
+	}
+
+	if c.decisionClient == nil {
+		result.Err = errors.New("Decision client is not initialized")
+		return result
+	}
+
+	resp, err := c.decisionClient.GetModifications(request.ID, request.AnonymousID, c.buildVisitorContext(request.Context))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	c.panicMode.update(resp)
+
+	result.Response = resp
+	result.Flags = getFlagInfos(resp)
+	return result
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/bucketing"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingDecisionClient struct {
+	calls int64
+}
+
+func (c *countingDecisionClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	atomic.AddInt64(&c.calls, 1)
+	return &model.APIClientResponse{VisitorID: visitorID}, nil
+}
+
+func TestGetDecisions(t *testing.T) {
+	client := createClient()
+	client.decisionClient = createMockClient()
+
+	requests := []VisitorRequest{}
+	for i := 0; i < 50; i++ {
+		requests = append(requests, VisitorRequest{ID: fmt.Sprintf("vid_%d", i), Context: model.Context{"index": i}})
+	}
+	requests = append(requests, VisitorRequest{ID: ""}, VisitorRequest{ID: "wrong_context", Context: model.Context{"wrong": errors.New("wrong type")}})
+
+	indexes := map[int]bool{}
+	for result := range client.GetDecisions(context.Background(), requests) {
+		indexes[result.Index] = true
+		assert.Equal(t, requests[result.Index].ID, result.VisitorID)
+		if result.Index < 50 {
+			assert.Nil(t, result.Err)
+			assert.Equal(t, "string", result.Flags["test_string"].Value)
+		} else {
+			assert.NotNil(t, result.Err)
+			assert.Nil(t, result.Response)
+		}
+	}
+	assert.Equal(t, len(requests), len(indexes))
+}
+
+func TestGetDecisionsBucketing(t *testing.T) {
+	client := createClient()
+	client.decisionClient = bucketing.GetBucketingEngineMock(testEnvID, nil)
+	client.decisionMode = Bucketing
+
+	requests := []VisitorRequest{}
+	for i := 0; i < 20; i++ {
+		requests = append(requests, VisitorRequest{ID: fmt.Sprintf("vid_%d", i), Context: model.Context{"test": true}})
+	}
+
+	count := 0
+	for result := range client.GetDecisions(context.Background(), requests) {
+		assert.Nil(t, result.Err)
+		assert.Equal(t, model.SOURCE_BUCKETING, result.Response.Source)
+		count++
+	}
+	assert.Equal(t, len(requests), count)
+}
+
+func TestGetDecisionsBackpressure(t *testing.T) {
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithBulkConcurrency(2))
+	client, _ := Create(options)
+	decisionClient := &countingDecisionClient{}
+	client.decisionClient = decisionClient
+
+	requests := make([]VisitorRequest, 100)
+	for i := range requests {
+		requests[i] = VisitorRequest{ID: fmt.Sprintf("vid_%d", i)}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := client.GetDecisions(ctx, requests)
+
+	// Workers wait for their results to be read before computing new decisions
+	<-results
+	time.Sleep(50 * time.Millisecond)
+	assert.LessOrEqual(t, atomic.LoadInt64(&decisionClient.calls), int64(3))
+
+	// Remaining visitors are skipped when the context is cancelled
+	cancel()
+	for range results {
+	}
+	assert.Less(t, atomic.LoadInt64(&decisionClient.calls), int64(len(requests)))
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
@@ -12,6 +13,7 @@ import (
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
 
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decisionapi"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/tracking"
 )
@@ -23,22 +25,30 @@ type DecisionMode string
 const (
 	API       DecisionMode = "API"
 	Bucketing DecisionMode = "Bucketing"
+	Hybrid    DecisionMode = "Hybrid"
 )
 
 const (
 	STATUS_INITIALIZING = "INITIALIZING"
 	STATUS_READY        = "READY"
+	STATUS_PANIC        = "PANIC"
 )
 
 // Client represent the Flagship SDK client object
 type Client struct {
-	envID             string
-	apiKey            string
-	decisionMode      DecisionMode
-	decisionClient    decision.ClientInterface
-	trackingAPIClient tracking.APIClientInterface
-	cacheManager      cache.Manager
-	status            string
+	envID              string
+	apiKey             string
+	decisionMode       DecisionMode
+	decisionClient     decision.ClientInterface
+	trackingAPIClient  tracking.APIClientInterface
+	cacheManager       cache.Manager
+	status             string
+	bulkConcurrency    int
+	panicMode          *panicMode
+	visitorIDGenerator func() string
+	baseContext        model.Context
+	sessionTracker     *sessionTracker
+	maxGetterWait      time.Duration
 }
 
 var clientLogger = logging.CreateLogger("FS Client")
@@ -59,11 +69,27 @@ func Create(f *Options) (*Client, error) {
 		return nil, err
 	}
 
+	// The context is copied as the validation converts its integer values
+	defaultContext := copyContext(f.defaultContext)
+	errs := defaultContext.Validate()
+	if len(errs) > 0 {
+		errorStrings := []string{}
+		for _, e := range errs {
+			errorStrings = append(errorStrings, e.Error())
+		}
+		return nil, fmt.Errorf("Invalid default context : %s", strings.Join(errorStrings, ", "))
+	}
+
 	client := &Client{
-		envID:             f.EnvID,
-		apiKey:            f.APIKey,
-		status:            STATUS_INITIALIZING,
-		trackingAPIClient: f.trackingAPIClient,
+		envID:              f.EnvID,
+		apiKey:             f.APIKey,
+		status:             STATUS_INITIALIZING,
+		trackingAPIClient:  f.trackingAPIClient,
+		bulkConcurrency:    f.bulkConcurrency,
+		panicMode:          &panicMode{},
+		visitorIDGenerator: f.visitorIDGenerator,
+		baseContext:        mergeContexts(getPredefinedContext(), defaultContext),
+		maxGetterWait:      f.maxGetterWait,
 	}
 
 	if len(f.cacheManagerOptions) > 0 {
@@ -74,34 +100,194 @@ func Create(f *Options) (*Client, error) {
 		client.cacheManager = cacheManager
 	}
 
+	if f.sessionTracking {
+		client.sessionTracker = newSessionTracker(f.sessionTimeout, client.cacheManager)
+	}
+
 	if client.trackingAPIClient == nil {
 		client.trackingAPIClient, err = tracking.NewAPIClient(client.envID, f.APIKey, f.decisionAPIOptions...)
 	}
 
 	if client.decisionClient == nil {
 		client.decisionMode = f.decisionMode
-		if f.decisionMode == Bucketing {
+		switch f.decisionMode {
+		case Bucketing:
 			client.decisionClient, err = bucketing.NewEngine(client.envID, client.cacheManager, f.bucketingOptions...)
 			if err != nil {
 				clientLogger.Error("Got error when creating bucketing engine", err)
 			}
-		} else {
-			client.decisionClient, err = decision.NewAPIClient(client.envID, f.APIKey, f.decisionAPIOptions...)
+		case Hybrid:
+			client.decisionClient, err = createHybridClient(client.envID, client.cacheManager, f)
+			if err != nil {
+				clientLogger.Error("Got error when creating hybrid engine", err)
+			}
+		default:
+			client.decisionClient, err = decision.NewAPIClient(client.envID, f.APIKey, getDecisionAPIOptions(client.cacheManager, f)...)
 			if err != nil {
 				clientLogger.Error("Got error when creating Decision API engine", err)
 			}
 		}
 	}
 
+	if f.shadowOptions != nil && client.decisionClient != nil {
+		if client.decisionMode == Hybrid {
+			clientLogger.Warn("Shadow comparison is not available in hybrid decision mode. Skipping shadow comparison")
+		} else {
+			client.createShadowClient(f)
+		}
+	}
+
+	if f.requestCoalescing && client.decisionClient != nil {
+		client.decisionClient = decision.NewCoalescingClient(client.decisionClient)
+	}
+
+	if f.decisionCacheOptions != nil && client.decisionClient != nil {
+		client.decisionClient = decision.NewCachedClient(client.decisionClient, *f.decisionCacheOptions)
+	}
+
 	client.status = STATUS_READY
 	return client, err
 }
 
+// getDecisionAPIOptions returns the Decision API client options, serving the visitor cache when the Decision API fails
+func getDecisionAPIOptions(cacheManager cache.Manager, f *Options) []func(*decisionapi.APIClient) {
+	if cacheManager == nil {
+		return f.decisionAPIOptions
+	}
+	return append([]func(*decisionapi.APIClient){decisionapi.CacheManager(cacheManager)}, f.decisionAPIOptions...)
+}
+
+// createHybridClient creates a Decision API client falling back to a bucketing engine
+func createHybridClient(envID string, cacheManager cache.Manager, f *Options) (decision.ClientInterface, error) {
+	apiClient, err := decision.NewAPIClient(envID, f.APIKey, getDecisionAPIOptions(cacheManager, f)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The bucketing engine loading error is not blocking as the configuration will be polled later
+	engine, engineErr := bucketing.NewEngine(envID, cacheManager, f.bucketingOptions...)
+	if engineErr != nil {
+		clientLogger.Warnf("Got error when loading hybrid bucketing engine: %v", engineErr)
+	}
+
+	return decision.NewHybridClient(apiClient, engine, f.hybridOptions), nil
+}
+
+// createShadowClient wraps the decision client to compare its decisions with the other decision mode
+func (c *Client) createShadowClient(f *Options) {
+	var shadow decision.ClientInterface
+	if c.decisionMode == Bucketing {
+		apiClient, err := decision.NewAPIClient(c.envID, f.APIKey, f.decisionAPIOptions...)
+		if err != nil {
+			clientLogger.Warnf("Got error when creating shadow Decision API client: %v", err)
+			return
+		}
+		shadow = apiClient
+	} else {
+		// The shadow engine does not use the cache manager so that served assignments are not altered
+		engine, err := bucketing.NewEngine(c.envID, nil, f.shadowEngineOptions...)
+		if err != nil {
+			clientLogger.Warnf("Got error when creating shadow bucketing engine: %v", err)
+		}
+		shadow = engine
+	}
+	c.decisionClient = decision.NewShadowClient(c.decisionClient, shadow, *f.shadowOptions)
+}
+
+// getDecisionClients returns the client decision client and all the clients it wraps, outermost first
+func (c *Client) getDecisionClients() []decision.ClientInterface {
+	decisionClients := []decision.ClientInterface{}
+	queue := []decision.ClientInterface{c.decisionClient}
+	for len(queue) > 0 {
+		decisionClient := queue[0]
+		queue = queue[1:]
+		if decisionClient == nil {
+			continue
+		}
+		decisionClients = append(decisionClients, decisionClient)
+		if hybridClient, ok := decisionClient.(*decision.HybridClient); ok {
+			queue = append(queue, hybridClient.GetPrimaryClient(), hybridClient.GetFallbackClient())
+		} else if wrapper, ok := decisionClient.(decision.WrapperInterface); ok {
+			queue = append(queue, wrapper.GetPrimaryClient())
+		}
+	}
+	return decisionClients
+}
+
+// findDecisionClient returns the first decision client, among the client decision client and the clients it wraps, matching the predicate
+func (c *Client) findDecisionClient(match func(decision.ClientInterface) bool) decision.ClientInterface {
+	for _, decisionClient := range c.getDecisionClients() {
+		if match(decisionClient) {
+			return decisionClient
+		}
+	}
+	return nil
+}
+
+// getBucketingEngine returns the bucketing engine serving the decisions, if any
+func (c *Client) getBucketingEngine() (*bucketing.Engine, bool) {
+	engine, ok := c.findDecisionClient(func(dc decision.ClientInterface) bool {
+		_, ok := dc.(*bucketing.Engine)
+		return ok
+	}).(*bucketing.Engine)
+	return engine, ok
+}
+
+// getShadowClient returns the shadow client comparing the decisions, if any
+func (c *Client) getShadowClient() (*decision.ShadowClient, bool) {
+	shadowClient, ok := c.findDecisionClient(func(dc decision.ClientInterface) bool {
+		_, ok := dc.(*decision.ShadowClient)
+		return ok
+	}).(*decision.ShadowClient)
+	return shadowClient, ok
+}
+
+// GetShadowStats returns the shadow comparison counters, and false if the shadow comparison is not enabled
+func (c *Client) GetShadowStats() (decision.ShadowStats, bool) {
+	shadowClient, ok := c.getShadowClient()
+	if !ok {
+		return decision.ShadowStats{}, false
+	}
+	return shadowClient.GetStats(), true
+}
+
+// GetDecisionCacheStats returns the decision cache counters, and false if the decision cache is not enabled
+func (c *Client) GetDecisionCacheStats() (decision.CacheStats, bool) {
+	cachedClient, ok := c.findDecisionClient(func(dc decision.ClientInterface) bool {
+		_, ok := dc.(*decision.CachedClient)
+		return ok
+	}).(*decision.CachedClient)
+	if !ok {
+		return decision.CacheStats{}, false
+	}
+	return cachedClient.GetStats(), true
+}
+
+// GetCoalescingStats returns the request coalescing counters, and false if the request coalescing is not enabled
+func (c *Client) GetCoalescingStats() (decision.CoalescingStats, bool) {
+	coalescingClient, ok := c.findDecisionClient(func(dc decision.ClientInterface) bool {
+		_, ok := dc.(*decision.CoalescingClient)
+		return ok
+	}).(*decision.CoalescingClient)
+	if !ok {
+		return decision.CoalescingStats{}, false
+	}
+	return coalescingClient.GetStats(), true
+}
+
 // GetStatus returns the current client status
 func (c *Client) GetStatus() string {
+	if c.panicMode.isEnabled() {
+		return STATUS_PANIC
+	}
 	return c.status
 }
 
+// IsPanic returns true if the Decision API reported the environment in panic mode in its last response
+func (c *Client) IsPanic() bool {
+	return c.panicMode.isEnabled()
+}
+
 // NewVisitor returns a new Visitor from ID and context
 func (c *Client) NewVisitor(visitorID string, context model.Context, options ...VisitorOptionBuilder) (visitor *Visitor, err error) {
 	defer func() {
@@ -125,27 +311,42 @@ func (c *Client) NewVisitor(visitorID string, context model.Context, options ...
 	id := visitorID
 	var anonymousID *string
 	if id == "" {
-		id = generateAnonymousID()
+		id = c.generateVisitorID()
 	}
 
 	// Build visitor options
-	visitorOptions := &VisitorOptions{}
+	visitorOptions := &VisitorOptions{
+		HasConsented: true,
+	}
 	visitorOptions.BuildVisitorOptions(options...)
 
 	// Set anonymous ID is visitor is created already authenticated
 	if visitorOptions.IsAuthenticated {
-		newAnonID := generateAnonymousID()
+		newAnonID := visitorOptions.AnonymousID
+		if newAnonID == "" {
+			newAnonID = c.generateVisitorID()
+		}
 		anonymousID = &newAnonID
 	}
 
 	return &Visitor{
 		ID:                id,
 		AnonymousID:       anonymousID,
-		Context:           context,
+		Context:           c.buildVisitorContext(context),
+		baseContext:       c.baseContext,
+		envID:             c.envID,
+		hasConsented:      visitorOptions.HasConsented,
 		decisionClient:    c.decisionClient,
 		decisionMode:      c.decisionMode,
 		trackingAPIClient: c.trackingAPIClient,
 		cacheManager:      c.cacheManager,
+		sessionTracker:    c.sessionTracker,
+		panicMode:         c.panicMode,
+		maxGetterWait:     c.maxGetterWait,
+		fetchStatus: FetchFlagsStatus{
+			Status: FETCH_STATUS_REQUIRED,
+			Reason: FETCH_REASON_VISITOR_CREATED,
+		},
 	}, nil
 }
 
@@ -157,7 +358,13 @@ func (c *Client) SendHit(visitorID string, anonymousID *string, hit model.HitInt
 		}
 	}()
 
+	if c.panicMode.isEnabled() {
+		clientLogger.Info("Environment is in panic mode. Skipping hit")
+		return nil
+	}
+
 	clientLogger.Info(fmt.Sprintf("Sending hit for visitor with id : %s", visitorID))
+	c.sessionTracker.stamp(visitorID, anonymousID, hit)
 	err = c.trackingAPIClient.SendHit(visitorID, anonymousID, hit)
 
 	if err != nil {
@@ -168,6 +375,9 @@ func (c *Client) SendHit(visitorID string, anonymousID *string, hit model.HitInt
 
 // Dispose disposes the Client and close all connections
 func (c *Client) Dispose() (err error) {
+	if shadowClient, ok := c.getShadowClient(); ok {
+		shadowClient.Close()
+	}
 	return err
 }
 
@@ -181,6 +391,22 @@ func (c *Client) GetDecisionMode() DecisionMode {
 	return c.decisionMode
 }
 
+// ListFlags returns the metadata of all the flags of the environment. Only available in bucketing and hybrid modes
+func (c *Client) ListFlags() (flags []*model.FlagMetadata, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = utils.HandleRecovered(r, clientLogger)
+		}
+	}()
+
+	engine, ok := c.getBucketingEngine()
+	if !ok {
+		return nil, errors.New("Flag catalogue is only available in bucketing and hybrid modes")
+	}
+
+	return engine.ListFlags()
+}
+
 // GetCacheManager returns the current cache manager
 func (c *Client) GetCacheManager() cache.Manager {
 	return c.cacheManager
@@ -255,3 +255,163 @@ func TestSendHitClient(t *testing.T) {
 		t.Errorf("Did not expect error as hit is correct. Got %v", err)
 	}
 }
+
+func TestListFlags(t *testing.T) {
+	client := createClient()
+
+	_, err := client.ListFlags()
+	assert.NotNil(t, err)
+
+	client.decisionClient = bucketing.GetBucketingEngineMock(testEnvID, nil)
+	client.decisionMode = Bucketing
+
+	flags, err := client.ListFlags()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(flags))
+	assert.Equal(t, "test", flags[0].Key)
+	assert.Equal(t, []string{"bool"}, flags[0].Types)
+}
+
+func TestCreateShadow(t *testing.T) {
+	client := createClient()
+	_, enabled := client.GetShadowStats()
+	assert.False(t, enabled)
+
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithShadowComparison(decision.ShadowOptions{SampleRate: 0.5}, bucketing.PollingInterval(-1)))
+
+	client, _ = Create(options)
+	shadowClient, ok := client.decisionClient.(*decision.ShadowClient)
+	assert.True(t, ok)
+	assert.IsType(t, &decision.APIClient{}, shadowClient.GetPrimaryClient())
+
+	stats, enabled := client.GetShadowStats()
+	assert.True(t, enabled)
+	assert.Equal(t, decision.ShadowStats{}, stats)
+
+	options = &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithBucketing(bucketing.PollingInterval(-1)), WithShadowComparison(decision.ShadowOptions{SampleRate: 0.5}))
+
+	client, _ = Create(options)
+	shadowClient, ok = client.decisionClient.(*decision.ShadowClient)
+	assert.True(t, ok)
+	assert.IsType(t, &bucketing.Engine{}, shadowClient.GetPrimaryClient())
+
+	_, ok = client.getBucketingEngine()
+	assert.True(t, ok)
+	assert.Nil(t, client.Dispose())
+
+	// The shadow comparison is not available in hybrid mode
+	options = &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithHybrid(decision.HybridOptions{}, bucketing.PollingInterval(-1)), WithShadowComparison(decision.ShadowOptions{SampleRate: 0.5}))
+	client, _ = Create(options)
+	_, enabled = client.GetShadowStats()
+	assert.False(t, enabled)
+}
+
+func TestCreateHybrid(t *testing.T) {
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithHybrid(decision.HybridOptions{FailureThreshold: 1}, bucketing.PollingInterval(-1)))
+
+	client, err := Create(options)
+	assert.Nil(t, err)
+	assert.Equal(t, Hybrid, client.GetDecisionMode())
+
+	hybridClient, ok := client.decisionClient.(*decision.HybridClient)
+	assert.True(t, ok)
+	assert.IsType(t, &decision.APIClient{}, hybridClient.GetPrimaryClient())
+	assert.IsType(t, &bucketing.Engine{}, hybridClient.GetFallbackClient())
+
+	_, ok = client.getBucketingEngine()
+	assert.True(t, ok)
+}
+
+func TestCreateHybridVisitorCache(t *testing.T) {
+	updatedAt := time.Now()
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(
+		WithDecisionAPI(decisionapi.APIUrl("http://127.0.0.1:1")),
+		WithHybrid(decision.HybridOptions{FailureThreshold: 1}, bucketing.PollingInterval(-1)),
+		WithVisitorCache(cache.WithCustomOptions(cache.CustomOptions{
+			Getter: func(visitorID string) (map[string]*cache.CampaignCache, error) {
+				return map[string]*cache.CampaignCache{caID: {
+					VariationGroupID: vgID,
+					VariationID:      "vid",
+					FlagKeys:         []string{"cached_flag"},
+					FlagValues:       map[string]interface{}{"cached_flag": "cached"},
+					UpdatedAt:        &updatedAt,
+				}}, nil
+			},
+			Setter: func(visitorID string, cache map[string]*cache.CampaignCache) error { return nil },
+		})),
+	)
+	client, _ := Create(options)
+
+	// The visitor cache is served as in Decision API mode when the Decision API fails
+	visitor, _ := client.NewVisitor(testVID, model.Context{})
+	assert.Nil(t, visitor.SynchronizeModifications())
+	assert.Equal(t, model.SOURCE_CACHE, visitor.GetDecisionSource())
+	value, _ := visitor.GetModificationString("cached_flag", "default", false)
+	assert.Equal(t, "cached", value)
+	assert.True(t, client.decisionClient.(*decision.HybridClient).IsCircuitOpen())
+}
+
+func TestCreateDecisionCache(t *testing.T) {
+	client := createClient()
+	_, enabled := client.GetDecisionCacheStats()
+	assert.False(t, enabled)
+
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithBucketing(bucketing.PollingInterval(-1)), WithDecisionCache(decision.CacheOptions{MaxSize: 10}))
+
+	client, _ = Create(options)
+	cachedClient, ok := client.decisionClient.(*decision.CachedClient)
+	assert.True(t, ok)
+	assert.IsType(t, &bucketing.Engine{}, cachedClient.GetPrimaryClient())
+
+	stats, enabled := client.GetDecisionCacheStats()
+	assert.True(t, enabled)
+	assert.Equal(t, decision.CacheStats{}, stats)
+
+	_, ok = client.getBucketingEngine()
+	assert.True(t, ok)
+}
+
+func TestCreateRequestCoalescing(t *testing.T) {
+	client := createClient()
+	_, enabled := client.GetCoalescingStats()
+	assert.False(t, enabled)
+
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithRequestCoalescing(), WithDecisionCache(decision.CacheOptions{}))
+
+	client, _ = Create(options)
+	cachedClient, ok := client.decisionClient.(*decision.CachedClient)
+	assert.True(t, ok)
+	assert.IsType(t, &decision.CoalescingClient{}, cachedClient.GetPrimaryClient())
+
+	stats, enabled := client.GetCoalescingStats()
+	assert.True(t, enabled)
+	assert.Equal(t, decision.CoalescingStats{}, stats)
+}
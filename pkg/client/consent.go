@@ -0,0 +1,19 @@
+package client
+
+import "github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+
+// HasConsented returns true if the visitor has consented to be tracked
+func (v *Visitor) HasConsented() bool {
+	return v.getState().hasConsented
+}
+
+// SetConsent sets the consent of the visitor to be tracked and sends it to the Data Collect API.
+// Without consent, no hit other than the consent hit nor activation is sent, and the visitor assignments are not cached.
+// The consent is sent to the Decision API at the next synchronization
+func (v *Visitor) SetConsent(hasConsented bool) error {
+	v.mu.Lock()
+	v.hasConsented = hasConsented
+	v.mu.Unlock()
+
+	return v.SendHit(model.NewConsentHit(hasConsented))
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingDecisionClient struct {
+	decision.ClientInterface
+	context      model.Context
+	hasConsented *bool
+}
+
+func (c *recordingDecisionClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	c.context = context
+	c.hasConsented = nil
+	return c.ClientInterface.GetModifications(visitorID, anonymousID, context)
+}
+
+func (c *recordingDecisionClient) GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	c.context = context
+	c.hasConsented = &hasConsented
+	return c.ClientInterface.GetModifications(visitorID, anonymousID, context)
+}
+
+type eventTrackingAPIClient struct {
+	countingTrackingAPIClient
+	mu     sync.Mutex
+	events []model.Event
+}
+
+func (c *eventTrackingAPIClient) SendEvent(event model.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+	return nil
+}
+
+func (c *eventTrackingAPIClient) getEvents() []model.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.events
+}
+
+func TestConsent(t *testing.T) {
+	client := createClient()
+	decisionClient := &recordingDecisionClient{ClientInterface: createMockClient()}
+	client.decisionClient = decisionClient
+	trackingAPIClient := &countingTrackingAPIClient{}
+	client.trackingAPIClient = trackingAPIClient
+
+	visitor, _ := client.NewVisitor(testVID, model.Context{"key": "value"})
+	assert.True(t, visitor.HasConsented())
+
+	visitor, _ = client.NewVisitor(testVID, model.Context{"key": "value"}, WithConsent(false))
+	assert.False(t, visitor.HasConsented())
+
+	// The consent is passed to the decision engine apart from the visitor context
+	assert.Nil(t, visitor.SynchronizeModifications())
+	assert.Equal(t, false, *decisionClient.hasConsented)
+	assert.Equal(t, client.buildVisitorContext(model.Context{"key": "value"}), decisionClient.context)
+	assert.Equal(t, client.buildVisitorContext(model.Context{"key": "value"}), visitor.GetContext())
+
+	// No hit nor activation is sent without consent, except the consent hit
+	value, _ := visitor.GetModificationString("test_string", "default", true)
+	assert.Equal(t, "string", value)
+	assert.Nil(t, visitor.ActivateModification("test_string"))
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	assert.Nil(t, visitor.SendHit(model.NewConsentHit(false)))
+	assert.Equal(t, int64(0), trackingAPIClient.activations)
+	assert.Equal(t, int64(1), trackingAPIClient.hits)
+
+	assert.Nil(t, visitor.SetConsent(true))
+	assert.True(t, visitor.HasConsented())
+	assert.Equal(t, int64(2), trackingAPIClient.hits)
+
+	assert.Nil(t, visitor.SynchronizeModifications())
+	assert.Equal(t, true, *decisionClient.hasConsented)
+	assert.Nil(t, visitor.ActivateModification("test_string"))
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	assert.Equal(t, int64(1), trackingAPIClient.activations)
+	assert.Equal(t, int64(3), trackingAPIClient.hits)
+}
+
+func TestConsentHit(t *testing.T) {
+	hit := model.NewConsentHit(true)
+	assert.Equal(t, "go:true", hit.Label)
+	assert.True(t, model.IsConsentHit(hit))
+	assert.False(t, model.IsConsentHit(&model.EventHit{Action: "action"}))
+	assert.False(t, model.IsConsentHit(&model.PageHit{}))
+
+	// Only the hit built by NewConsentHit is a consent hit
+	assert.False(t, model.IsConsentHit(&model.EventHit{Action: hit.Action}))
+	hit.Value = 10
+	assert.False(t, model.IsConsentHit(hit))
+	hit = model.NewConsentHit(false)
+	hit.DocumentLocation = "https://example.com"
+	assert.False(t, model.IsConsentHit(hit))
+}
+
+func TestConsentContextEvent(t *testing.T) {
+	client := createClient()
+	client.decisionMode = Bucketing
+	client.decisionClient = createMockClient()
+	trackingAPIClient := &eventTrackingAPIClient{}
+	client.trackingAPIClient = trackingAPIClient
+
+	// The context of the visitors who did not consent is not sent to the events endpoint
+	visitor, _ := client.NewVisitor(testVID, model.Context{"key": "value"}, WithConsent(false))
+	assert.Nil(t, visitor.SynchronizeModifications())
+
+	visitor, _ = client.NewVisitor(testVID, model.Context{"key": "value"})
+	assert.Nil(t, visitor.SynchronizeModifications())
+	assert.Eventually(t, func() bool { return len(trackingAPIClient.getEvents()) == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	events := trackingAPIClient.getEvents()
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, model.CONTEXT, events[0].Type)
+	assert.Equal(t, "value", events[0].Data["key"])
+}
+
+func TestEvaluateConsent(t *testing.T) {
+	client := createClient()
+	client.decisionMode = Bucketing
+	decisionClient := &recordingDecisionClient{ClientInterface: createMockClient()}
+	client.decisionClient = decisionClient
+	trackingAPIClient := &eventTrackingAPIClient{}
+	client.trackingAPIClient = trackingAPIClient
+
+	// As for the visitors, the consent is passed to the decision engine, and neither the context nor the activations are sent without it
+	hasConsented := false
+	flagSet, err := client.Evaluate(context.Background(), testVID, model.Context{"key": "value"}, EvaluateOptions{HasConsented: &hasConsented})
+	assert.Nil(t, err)
+	assert.Equal(t, false, *decisionClient.hasConsented)
+	assert.Nil(t, flagSet.Activate("test_string"))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&trackingAPIClient.activations))
+
+	// Visitors have consented by default. The flags are activated at each call, as for the visitors
+	flagSet, err = client.Evaluate(context.Background(), testVID, model.Context{"key": "value"}, EvaluateOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, true, *decisionClient.hasConsented)
+	assert.Nil(t, flagSet.Activate("test_string"))
+	assert.Nil(t, flagSet.Activate("test_string"))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&trackingAPIClient.activations))
+
+	visitor, _ := client.NewVisitor(testVID, model.Context{"key": "value"})
+	assert.Nil(t, visitor.SynchronizeModifications())
+	assert.Nil(t, visitor.ActivateModification("test_string"))
+	assert.Nil(t, visitor.ActivateModification("test_string"))
+	assert.Equal(t, int64(4), atomic.LoadInt64(&trackingAPIClient.activations))
+
+	assert.Eventually(t, func() bool { return len(trackingAPIClient.getEvents()) == 2 }, time.Second, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	events := trackingAPIClient.getEvents()
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, model.CONTEXT, events[0].Type)
+	assert.Equal(t, "value", events[0].Data["key"])
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
+)
+
+// getPredefinedContext returns the context keys computed by the SDK
+func getPredefinedContext() model.Context {
+	context := model.Context{
+		model.FS_CLIENT_CONTEXT_KEY:  model.FS_CLIENT_NAME,
+		model.FS_VERSION_CONTEXT_KEY: utils.PKG_VERSION,
+		model.OS_NAME_CONTEXT_KEY:    runtime.GOOS,
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		clientLogger.Warn("Could not get the hostname for the visitor context: ", err)
+	} else {
+		context[model.HOSTNAME_CONTEXT_KEY] = hostname
+	}
+	return context
+}
+
+// mergeContexts returns a new context with the keys of all the contexts. The last contexts take precedence
+func mergeContexts(contexts ...model.Context) model.Context {
+	merged := model.Context{}
+	for _, context := range contexts {
+		for k, val := range context {
+			merged[k] = val
+		}
+	}
+	return merged
+}
+
+// buildVisitorContext returns the visitor context merged into the client base context.
+// The visitor context takes precedence over the default context, which takes precedence over the predefined keys,
+// except the reserved keys that can only be set by the SDK
+func (c *Client) buildVisitorContext(context model.Context) model.Context {
+	return mergeContexts(c.baseContext, context)
+}
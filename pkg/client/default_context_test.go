@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultContext(t *testing.T) {
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithDefaultContext(model.Context{model.FS_CLIENT_CONTEXT_KEY: "other"}))
+	_, err := Create(options)
+	assert.NotNil(t, err)
+
+	options.BuildOptions(WithDefaultContext(model.Context{
+		"app_version":             "1.0.0",
+		"region":                  "eu",
+		"replicas":                3,
+		model.OS_NAME_CONTEXT_KEY: "custom_os",
+	}))
+	client, err := Create(options)
+	assert.Nil(t, err)
+
+	// The visitor visitorContext takes precedence over the default visitorContext, which takes precedence over the predefined keys
+	visitor, err := client.NewVisitor(testVID, model.Context{"region": "us"})
+	assert.Nil(t, err)
+	visitorContext := visitor.GetContext()
+	assert.Equal(t, model.FS_CLIENT_NAME, visitorContext[model.FS_CLIENT_CONTEXT_KEY])
+	assert.Equal(t, utils.PKG_VERSION, visitorContext[model.FS_VERSION_CONTEXT_KEY])
+	assert.Equal(t, "custom_os", visitorContext[model.OS_NAME_CONTEXT_KEY])
+	assert.Equal(t, "1.0.0", visitorContext["app_version"])
+	assert.Equal(t, 3., visitorContext["replicas"])
+	assert.Equal(t, "us", visitorContext["region"])
+
+	// The reserved keys can not be set by the visitor
+	_, err = client.NewVisitor(testVID, model.Context{model.FS_VERSION_CONTEXT_KEY: "v0"})
+	assert.NotNil(t, err)
+	assert.NotNil(t, visitor.UpdateContextKey(model.FS_CLIENT_CONTEXT_KEY, "other"))
+	assert.Equal(t, model.FS_CLIENT_NAME, visitor.GetContext()[model.FS_CLIENT_CONTEXT_KEY])
+
+	// The default and predefined keys are kept when the visitorContext is updated
+	assert.Nil(t, visitor.UpdateContext(model.Context{"key": "value"}))
+	visitorContext = visitor.GetContext()
+	assert.Equal(t, "value", visitorContext["key"])
+	assert.Equal(t, "eu", visitorContext["region"])
+	assert.Equal(t, model.FS_CLIENT_NAME, visitorContext[model.FS_CLIENT_CONTEXT_KEY])
+
+	assert.Nil(t, visitor.UpdateContextKey("region", "us"))
+	assert.Equal(t, "us", visitor.GetContext()["region"])
+
+	// The visitor visitorContext can be passed back as is, with the SDK values of the reserved keys
+	visitorContext = visitor.GetContext()
+	assert.Nil(t, visitor.UpdateContext(visitorContext))
+	otherVisitor, err := client.NewVisitor("other", visitorContext)
+	assert.Nil(t, err)
+	assert.Equal(t, visitorContext, otherVisitor.GetContext())
+
+	// The consent can only be set with the visitor options
+	_, err = client.NewVisitor(testVID, model.Context{model.CONSENT_CONTEXT_KEY: false})
+	assert.NotNil(t, err)
+	assert.NotNil(t, visitor.UpdateContextKey(model.CONSENT_CONTEXT_KEY, false))
+	_, err = client.Evaluate(context.Background(), testVID, model.Context{model.CONSENT_CONTEXT_KEY: false}, EvaluateOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestPredefinedContext(t *testing.T) {
+	client := createClient()
+	visitor, _ := client.NewVisitor(testVID, model.Context{})
+	context := visitor.GetContext()
+	assert.Equal(t, model.FS_CLIENT_NAME, context[model.FS_CLIENT_CONTEXT_KEY])
+	assert.Equal(t, utils.PKG_VERSION, context[model.FS_VERSION_CONTEXT_KEY])
+	assert.Equal(t, runtime.GOOS, context[model.OS_NAME_CONTEXT_KEY])
+	assert.NotEmpty(t, context[model.HOSTNAME_CONTEXT_KEY])
+}
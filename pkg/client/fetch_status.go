@@ -0,0 +1,98 @@
+package client
+
+import "github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+
+// FetchStatus represents the synchronization status of the visitor flags
+type FetchStatus string
+
+// FetchReason represents the reason why the visitor flags should be synchronized
+type FetchReason string
+
+// The different fetch statuses
+const (
+	FETCH_STATUS_REQUIRED FetchStatus = "FETCH_REQUIRED"
+	FETCH_STATUS_FETCHED  FetchStatus = "FETCHED"
+)
+
+// The different fetch reasons
+const (
+	FETCH_REASON_NONE            FetchReason = "NONE"
+	FETCH_REASON_VISITOR_CREATED FetchReason = "VISITOR_CREATED"
+	FETCH_REASON_CONTEXT_CHANGED FetchReason = "CONTEXT_CHANGED"
+	FETCH_REASON_AUTHENTICATED   FetchReason = "AUTHENTICATED"
+	FETCH_REASON_UNAUTHENTICATED FetchReason = "UNAUTHENTICATED"
+	FETCH_REASON_CONFIG_UPDATED  FetchReason = "CONFIG_UPDATED"
+)
+
+// FetchFlagsStatus represents the synchronization status of the visitor flags and the reason of this status
+type FetchFlagsStatus struct {
+	Status FetchStatus
+	Reason FetchReason
+}
+
+// requireFetch sets the visitor fetch status as required, keeping the first reason if a fetch is already required.
+// It must be called with the visitor lock held
+func (v *Visitor) requireFetch(reason FetchReason) {
+	v.fetchRequests++
+	if v.fetchStatus.Status == FETCH_STATUS_REQUIRED {
+		return
+	}
+	v.fetchStatus = FetchFlagsStatus{
+		Status: FETCH_STATUS_REQUIRED,
+		Reason: reason,
+	}
+}
+
+// refreshFetchStatus requires a fetch if the decision engine configuration changed since the last synchronization.
+// It must be called with the visitor lock held
+func (v *Visitor) refreshFetchStatus() {
+	targetingInfo, ok := v.decisionClient.(decision.TargetingInfoInterface)
+	if ok && v.fetchStatus.Status == FETCH_STATUS_FETCHED && targetingInfo.GetConfigVersion() != v.configVersion {
+		v.requireFetch(FETCH_REASON_CONFIG_UPDATED)
+	}
+}
+
+// isContextChangeRelevant checks if the context change impacts the targeting of the decision engine.
+// If the decision engine does not know the targeting, any change is relevant. It must be called with the visitor lock held
+func (v *Visitor) isContextChangeRelevant(newContext map[string]interface{}) bool {
+	targetingInfo, ok := v.decisionClient.(decision.TargetingInfoInterface)
+	isRelevant := func(key string) bool {
+		return !ok || targetingInfo.IsTargetingContextKey(key)
+	}
+
+	for k, val := range newContext {
+		oldVal, exists := v.Context[k]
+		if (!exists || oldVal != val) && isRelevant(k) {
+			return true
+		}
+	}
+	for k := range v.Context {
+		if _, exists := newContext[k]; !exists && isRelevant(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetFetchStatus returns the synchronization status of the visitor flags
+func (v *Visitor) GetFetchStatus() FetchFlagsStatus {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.refreshFetchStatus()
+	return v.fetchStatus
+}
+
+// SynchronizeIfNeeded synchronizes the visitor modifications only if the fetch status requires it
+func (v *Visitor) SynchronizeIfNeeded() error {
+	v.mu.Lock()
+	v.refreshFetchStatus()
+	isFetched := v.fetchStatus.Status == FETCH_STATUS_FETCHED
+	visitorID := v.ID
+	v.mu.Unlock()
+
+	if isFetched {
+		visitorLogger.Infof("Flags of visitor %s are up to date. Skipping synchronization", visitorID)
+		return nil
+	}
+	return v.SynchronizeModifications()
+}
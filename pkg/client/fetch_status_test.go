@@ -0,0 +1,116 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/bucketing"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	bucketingProto "github.com/flagship-io/flagship-proto/bucketing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchStatusAPI(t *testing.T) {
+	visitor := createVisitor("test", nil)
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_REQUIRED, Reason: FETCH_REASON_VISITOR_CREATED}, visitor.GetFetchStatus())
+
+	err := visitor.SynchronizeIfNeeded()
+	assert.Nil(t, err)
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_FETCHED, Reason: FETCH_REASON_NONE}, visitor.GetFetchStatus())
+
+	// Sync is skipped when nothing changed
+	visitor.decisionClient = decision.NewAPIClientMock(testEnvID, nil, 500)
+	err = visitor.SynchronizeIfNeeded()
+	assert.Nil(t, err)
+
+	// Any context change is relevant in API mode
+	_ = visitor.UpdateContextKey("any_key", "value")
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_REQUIRED, Reason: FETCH_REASON_CONTEXT_CHANGED}, visitor.GetFetchStatus())
+
+	err = visitor.SynchronizeIfNeeded()
+	assert.NotNil(t, err)
+	assert.Equal(t, FETCH_STATUS_REQUIRED, visitor.GetFetchStatus().Status)
+
+	visitor.decisionClient = createMockClient()
+	err = visitor.SynchronizeIfNeeded()
+	assert.Nil(t, err)
+
+	// Same context does not require a fetch
+	_ = visitor.UpdateContext(model.Context{"any_key": "value"})
+	assert.Equal(t, FETCH_STATUS_FETCHED, visitor.GetFetchStatus().Status)
+
+	_ = visitor.Authenticate("logged", nil, false)
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_REQUIRED, Reason: FETCH_REASON_AUTHENTICATED}, visitor.GetFetchStatus())
+
+	_ = visitor.Unauthenticate(nil, true)
+	assert.Equal(t, FETCH_STATUS_FETCHED, visitor.GetFetchStatus().Status)
+
+	_ = visitor.Authenticate("logged", nil, true)
+	_ = visitor.Unauthenticate(nil, false)
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_REQUIRED, Reason: FETCH_REASON_UNAUTHENTICATED}, visitor.GetFetchStatus())
+}
+
+func TestFetchStatusBucketing(t *testing.T) {
+	client := createClient()
+	engine := bucketing.GetBucketingEngineMock(testEnvID, nil)
+	client.decisionClient = engine
+	client.decisionMode = Bucketing
+	client.trackingAPIClient = &FakeTrackingAPIClient{}
+
+	visitor, _ := client.NewVisitor("test", model.Context{"test": true})
+	err := visitor.SynchronizeIfNeeded()
+	assert.Nil(t, err)
+	assert.Equal(t, FETCH_STATUS_FETCHED, visitor.GetFetchStatus().Status)
+
+	// Context keys not used by targeting do not require a fetch
+	_ = visitor.UpdateContextKey("not_targeted", "value")
+	assert.Equal(t, FETCH_STATUS_FETCHED, visitor.GetFetchStatus().Status)
+
+	_ = visitor.UpdateContextKey("test", false)
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_REQUIRED, Reason: FETCH_REASON_CONTEXT_CHANGED}, visitor.GetFetchStatus())
+
+	_ = visitor.SynchronizeIfNeeded()
+	assert.Equal(t, FETCH_STATUS_FETCHED, visitor.GetFetchStatus().Status)
+
+	// Loading a new configuration requires a fetch
+	_ = engine.Load()
+	assert.Equal(t, FETCH_STATUS_FETCHED, visitor.GetFetchStatus().Status)
+
+	bucketing.SetMockConfig(engine, &bucketingProto.Bucketing_BucketingResponse{})
+	_ = engine.Load()
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_REQUIRED, Reason: FETCH_REASON_CONFIG_UPDATED}, visitor.GetFetchStatus())
+}
+
+type reloadingDecisionClient struct {
+	decision.ClientInterface
+	version int64
+}
+
+// GetModifications simulates a configuration loaded while the decision is computed on the previous configuration
+func (c *reloadingDecisionClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	resp, err := c.ClientInterface.GetModifications(visitorID, anonymousID, context)
+	c.version++
+	return resp, err
+}
+
+func (c *reloadingDecisionClient) GetConfigVersion() int64 {
+	return c.version
+}
+
+func (c *reloadingDecisionClient) IsTargetingContextKey(key string) bool {
+	return true
+}
+
+func TestFetchStatusConfigReloadedDuringSync(t *testing.T) {
+	client := createClient()
+	decisionClient := &reloadingDecisionClient{ClientInterface: createMockClient(), version: 1}
+	client.decisionClient = decisionClient
+
+	// The flags computed on the previous configuration are still outdated
+	visitor, _ := client.NewVisitor(testVID, model.Context{})
+	assert.Nil(t, visitor.SynchronizeModifications())
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_REQUIRED, Reason: FETCH_REASON_CONFIG_UPDATED}, visitor.GetFetchStatus())
+
+	assert.Nil(t, visitor.SynchronizeIfNeeded())
+	assert.Equal(t, int64(3), decisionClient.version)
+}
@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/tracking"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
+)
+
+// EvaluateOptions represents the options of a stateless flag evaluation
+type EvaluateOptions struct {
+	AnonymousID *string
+	// HasConsented is the consent of the visitor to be tracked. Visitors have consented if it is nil
+	HasConsented *bool
+}
+
+// FlagSet is an immutable snapshot of the flags of a visitor, safe for concurrent use
+type FlagSet struct {
+	visitorID         string
+	anonymousID       *string
+	source            model.DecisionSource
+	flagInfos         map[string]model.FlagInfos
+	hasConsented      bool
+	trackingAPIClient tracking.APIClientInterface
+	panicMode         *panicMode
+}
+
+type evaluation struct {
+	resp *model.APIClientResponse
+	err  error
+}
+
+// Evaluate computes the flags of a visitor without creating a Visitor object.
+// It returns the context error if the context is done before the decision is computed
+func (c *Client) Evaluate(ctx context.Context, visitorID string, visitorContext model.Context, options EvaluateOptions) (flagSet *FlagSet, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = utils.HandleRecovered(r, clientLogger)
+		}
+	}()
+
+	if visitorID == "" {
+		return nil, errors.New("Visitor ID should not be empty")
+	}
+
+	if c.decisionClient == nil {
+		return nil, errors.New("Decision client is not initialized")
+	}
+
+	// The context is copied as the validation converts its integer values
+	evalContext := copyContext(visitorContext)
+	errs := evalContext.Validate()
+	if len(errs) > 0 {
+		errorStrings := []string{}
+		for _, e := range errs {
+			errorStrings = append(errorStrings, e.Error())
+		}
+		return nil, fmt.Errorf("Invalid context : %s", strings.Join(errorStrings, ", "))
+	}
+	evalContext = c.buildVisitorContext(evalContext)
+
+	var anonymousID *string
+	if options.AnonymousID != nil {
+		anonID := *options.AnonymousID
+		anonymousID = &anonID
+	}
+
+	hasConsented := true
+	if options.HasConsented != nil {
+		hasConsented = *options.HasConsented
+	}
+
+	done := make(chan evaluation, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- evaluation{err: utils.HandleRecovered(r, clientLogger)}
+			}
+		}()
+		resp, err := decision.GetModificationsWithConsent(c.decisionClient, visitorID, anonymousID, evalContext, hasConsented)
+		done <- evaluation{resp: resp, err: err}
+	}()
+
+	var result evaluation
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if result.err != nil {
+		return nil, result.err
+	}
+	c.panicMode.update(result.resp)
+
+	sendContextEvent(c.trackingAPIClient, c.decisionMode, result.resp, visitorID, evalContext, hasConsented)
+
+	return &FlagSet{
+		visitorID:         visitorID,
+		anonymousID:       anonymousID,
+		source:            result.resp.Source,
+		flagInfos:         getFlagInfos(result.resp),
+		hasConsented:      hasConsented,
+		trackingAPIClient: c.trackingAPIClient,
+		panicMode:         c.panicMode,
+	}, nil
+}
+
+// copyFlagValue deep copies object and array flag values so that callers cannot alter the flag set
+func copyFlagValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			copied[k] = copyFlagValue(val)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, val := range v {
+			copied[i] = copyFlagValue(val)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+// GetVisitorID returns the ID of the evaluated visitor
+func (fs *FlagSet) GetVisitorID() string {
+	return fs.visitorID
+}
+
+// GetDecisionSource returns the source that computed the flags
+func (fs *FlagSet) GetDecisionSource() model.DecisionSource {
+	return fs.source
+}
+
+// Keys returns the sorted keys of the flags
+func (fs *FlagSet) Keys() []string {
+	keys := make([]string, 0, len(fs.flagInfos))
+	for k := range fs.flagInfos {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Has returns true if the flag is set for the visitor
+func (fs *FlagSet) Has(key string) bool {
+	_, ok := fs.flagInfos[key]
+	return ok
+}
+
+// getValue gets a flag value as interface{}, and activates it if needed
+func (fs *FlagSet) getValue(key string, activate bool) interface{} {
+	flagInfos, ok := fs.flagInfos[key]
+	if !ok {
+		clientLogger.Infof("key %s not set in flag set.", key)
+		return nil
+	}
+
+	if activate {
+		err := fs.Activate(key)
+		if err != nil {
+			clientLogger.Debug(fmt.Sprintf("Error occurred when activating campaign : %v.", err))
+		}
+	}
+	return copyFlagValue(flagInfos.Value)
+}
+
+// GetBool gets a flag value as bool by its key
+func (fs *FlagSet) GetBool(key string, defaultValue bool, activate bool) (bool, error) {
+	val := fs.getValue(key, activate)
+	if val == nil {
+		return defaultValue, nil
+	}
+
+	castVal, ok := val.(bool)
+	if !ok {
+		return defaultValue, fmt.Errorf("Key value cast error : expected bool, got %v", val)
+	}
+	return castVal, nil
+}
+
+// GetString gets a flag value as string by its key
+func (fs *FlagSet) GetString(key string, defaultValue string, activate bool) (string, error) {
+	val := fs.getValue(key, activate)
+	if val == nil {
+		return defaultValue, nil
+	}
+
+	castVal, ok := val.(string)
+	if !ok {
+		return defaultValue, fmt.Errorf("Key value cast error : expected string, got %v", val)
+	}
+	return castVal, nil
+}
+
+// GetNumber gets a flag value as float64 by its key
+func (fs *FlagSet) GetNumber(key string, defaultValue float64, activate bool) (float64, error) {
+	val := fs.getValue(key, activate)
+	if val == nil {
+		return defaultValue, nil
+	}
+
+	castVal, ok := val.(float64)
+	if !ok {
+		return defaultValue, fmt.Errorf("Key value cast error : expected float64, got %v", val)
+	}
+	return castVal, nil
+}
+
+// GetObject gets a copy of a flag value as map[string]interface{} by its key
+func (fs *FlagSet) GetObject(key string, defaultValue map[string]interface{}, activate bool) (map[string]interface{}, error) {
+	val := fs.getValue(key, activate)
+	if val == nil {
+		return defaultValue, nil
+	}
+
+	castVal, ok := val.(map[string]interface{})
+	if !ok {
+		return defaultValue, fmt.Errorf("Key value cast error : expected map[string]interface{}, got %v", val)
+	}
+	return castVal, nil
+}
+
+// GetArray gets a copy of a flag value as []interface{} by its key
+func (fs *FlagSet) GetArray(key string, defaultValue []interface{}, activate bool) ([]interface{}, error) {
+	val := fs.getValue(key, activate)
+	if val == nil {
+		return defaultValue, nil
+	}
+
+	castVal, ok := val.([]interface{})
+	if !ok {
+		return defaultValue, fmt.Errorf("Key value cast error : expected []interface{}, got %v", val)
+	}
+	return castVal, nil
+}
+
+// GetModificationInfo returns a flag info by its key, or nil if the flag is not set
+func (fs *FlagSet) GetModificationInfo(key string) *ModificationInfo {
+	flagInfos, ok := fs.flagInfos[key]
+	if !ok {
+		return nil
+	}
+
+	return &ModificationInfo{
+		CampaignID:       flagInfos.Campaign.ID,
+		VariationGroupID: flagInfos.Campaign.VariationGroupID,
+		VariationID:      flagInfos.Campaign.Variation.ID,
+		IsReference:      flagInfos.Campaign.Variation.Reference,
+		Value:            copyFlagValue(flagInfos.Value),
+	}
+}
+
+// Activate notifies Flagship that the visitor has seen the flag.
+// As for the visitors, the flag is activated at each call, but not in panic mode nor without consent
+func (fs *FlagSet) Activate(key string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = utils.HandleRecovered(r, clientLogger)
+		}
+	}()
+
+	flagInfos, ok := fs.flagInfos[key]
+	if !ok {
+		clientLogger.Infof("key %s not set in flag set. Skipping", key)
+		return nil
+	}
+
+	if fs.panicMode.isEnabled() {
+		clientLogger.Info("Environment is in panic mode. Skipping activation")
+		return nil
+	}
+
+	if !fs.hasConsented {
+		clientLogger.Info("Visitor has not consented to be tracked. Skipping activation")
+		return nil
+	}
+
+	if fs.trackingAPIClient == nil {
+		return errors.New("Tracking API client is not initialized")
+	}
+
+	return fs.trackingAPIClient.ActivateCampaign(model.ActivationHit{
+		VariationGroupID: flagInfos.Campaign.VariationGroupID,
+		VariationID:      flagInfos.Campaign.Variation.ID,
+		VisitorID:        fs.visitorID,
+		AnonymousID:      fs.anonymousID,
+	})
+}
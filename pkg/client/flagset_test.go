@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingTrackingAPIClient struct {
+	FakeTrackingAPIClient
+	activations int64
+	hits        int64
+}
+
+func (c *countingTrackingAPIClient) ActivateCampaign(request model.ActivationHit) error {
+	atomic.AddInt64(&c.activations, 1)
+	return nil
+}
+
+func (c *countingTrackingAPIClient) SendHit(visitorID string, anonymousID *string, hit model.HitInterface) error {
+	atomic.AddInt64(&c.hits, 1)
+	return nil
+}
+
+type slowDecisionClient struct{}
+
+func (*slowDecisionClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	time.Sleep(time.Second)
+	return &model.APIClientResponse{VisitorID: visitorID}, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	client := createClient()
+	client.decisionClient = createMockClient()
+	trackingAPIClient := &countingTrackingAPIClient{}
+	client.trackingAPIClient = trackingAPIClient
+
+	_, err := client.Evaluate(context.Background(), "", nil, EvaluateOptions{})
+	assert.NotNil(t, err)
+
+	visitorContext := model.Context{"test_int": 1}
+	flagSet, err := client.Evaluate(context.Background(), testVID, visitorContext, EvaluateOptions{})
+	assert.Nil(t, err)
+	assert.IsType(t, 1, visitorContext["test_int"])
+	assert.Equal(t, testVID, flagSet.GetVisitorID())
+	assert.Equal(t, []string{"test_array", "test_bool", "test_nil", "test_number", "test_object", "test_string"}, flagSet.Keys())
+	assert.True(t, flagSet.Has("test_bool"))
+	assert.False(t, flagSet.Has("not_exists"))
+
+	boolVal, err := flagSet.GetBool("test_bool", false, false)
+	assert.Nil(t, err)
+	assert.True(t, boolVal)
+
+	stringVal, err := flagSet.GetString("test_bool", "default", false)
+	assert.NotNil(t, err)
+	assert.Equal(t, "default", stringVal)
+
+	stringVal, _ = flagSet.GetString("test_string", "default", false)
+	assert.Equal(t, "string", stringVal)
+
+	numberVal, _ := flagSet.GetNumber("not_exists", 10, false)
+	assert.Equal(t, 10., numberVal)
+
+	numberVal, _ = flagSet.GetNumber("test_nil", 10, false)
+	assert.Equal(t, 10., numberVal)
+
+	// Returned objects and arrays are copies of the flag values
+	objectVal, _ := flagSet.GetObject("test_object", nil, false)
+	objectVal["test_key"] = false
+	objectVal, _ = flagSet.GetObject("test_object", nil, false)
+	assert.Equal(t, map[string]interface{}{"test_key": true}, objectVal)
+
+	arrayVal, _ := flagSet.GetArray("test_array", nil, false)
+	arrayVal[0] = false
+	arrayVal, _ = flagSet.GetArray("test_array", nil, false)
+	assert.Equal(t, []interface{}{true}, arrayVal)
+
+	info := flagSet.GetModificationInfo("test_string")
+	assert.Equal(t, caID, info.CampaignID)
+	assert.Equal(t, vgID, info.VariationGroupID)
+	assert.Nil(t, flagSet.GetModificationInfo("not_exists"))
+
+	assert.Equal(t, int64(0), atomic.LoadInt64(&trackingAPIClient.activations))
+	_, _ = flagSet.GetBool("test_bool", false, true)
+	assert.Nil(t, flagSet.Activate("test_string"))
+	assert.Nil(t, flagSet.Activate("not_exists"))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&trackingAPIClient.activations))
+}
+
+func TestEvaluateContextDone(t *testing.T) {
+	client := createClient()
+	client.decisionClient = &slowDecisionClient{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Evaluate(ctx, testVID, nil, EvaluateOptions{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestFlagSetConcurrency(t *testing.T) {
+	client := createClient()
+	client.decisionClient = createMockClient()
+	client.trackingAPIClient = &countingTrackingAPIClient{}
+
+	flagSet, _ := client.Evaluate(context.Background(), testVID, nil, EvaluateOptions{})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			objectVal, _ := flagSet.GetObject("test_object", nil, true)
+			objectVal["test_key"] = false
+			_, _ = flagSet.GetBool("test_bool", false, true)
+			_ = flagSet.Keys()
+		}()
+	}
+	wg.Wait()
+
+	objectVal, _ := flagSet.GetObject("test_object", nil, false)
+	assert.Equal(t, map[string]interface{}{"test_key": true}, objectVal)
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
+)
+
+// ForgetReport represents the visitor data removed by Client.ForgetVisitor
+type ForgetReport struct {
+	// VisitorIDs are the visitor ID and the other IDs linked to it, whose data have been removed
+	VisitorIDs []string
+	// CacheEntries are the IDs whose assignments have been deleted from the cache manager
+	CacheEntries []string
+	// DecisionCacheEntries is the number of decision responses removed from memory
+	DecisionCacheEntries int
+}
+
+// ForgetVisitor removes all the data stored by the SDK for the visitor and the IDs linked to it, and reports what was removed.
+// Hits are sent as soon as they are registered, so no pending hit is kept for the visitor
+func (c *Client) ForgetVisitor(visitorID string) (report ForgetReport, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = utils.HandleRecovered(r, clientLogger)
+		}
+	}()
+
+	if visitorID == "" {
+		return report, errors.New("Visitor ID should not be empty")
+	}
+
+	clientLogger.Info(fmt.Sprintf("Forgetting visitor with id : %s", visitorID))
+
+	// The in-memory data may link the visitor ID to anonymous or authenticated IDs,
+	// and the cached assignments reconciled with experience continuity link it to anonymous IDs
+	visitorIDs := []string{visitorID}
+	seen := map[string]bool{visitorID: true}
+	addLinkedIDs := func(linkedIDs []string) {
+		for _, id := range linkedIDs {
+			if !seen[id] {
+				seen[id] = true
+				visitorIDs = append(visitorIDs, id)
+			}
+		}
+	}
+	for i := 0; i < len(visitorIDs); i++ {
+		for _, decisionClient := range c.getDecisionClients() {
+			forgetter, ok := decisionClient.(decision.ForgetterInterface)
+			if !ok {
+				continue
+			}
+			removed, linkedIDs := forgetter.ForgetVisitor(visitorIDs[i])
+			report.DecisionCacheEntries += removed
+			addLinkedIDs(linkedIDs)
+		}
+		if c.cacheManager != nil {
+			if campaignsCache, err := c.cacheManager.Get(visitorIDs[i]); err == nil {
+				addLinkedIDs(cache.CampaignCacheMap(campaignsCache).GetAnonymousIDs())
+			}
+		}
+	}
+	report.VisitorIDs = visitorIDs
+
+	if c.sessionTracker != nil {
+		for _, id := range visitorIDs {
+			c.sessionTracker.forget(id)
+		}
+	}
+
+	if c.cacheManager == nil {
+		return report, nil
+	}
+
+	errorStrings := []string{}
+	for _, id := range visitorIDs {
+		campaignsCache, getErr := c.cacheManager.Get(id)
+		deleteErr := cache.Delete(c.cacheManager, id)
+		if deleteErr != nil {
+			errorStrings = append(errorStrings, fmt.Sprintf("%s: %v", id, deleteErr))
+			continue
+		}
+		if getErr == nil && len(campaignsCache) > 0 {
+			report.CacheEntries = append(report.CacheEntries, id)
+		}
+	}
+
+	if len(errorStrings) > 0 {
+		return report, fmt.Errorf("Error when deleting visitor cache : %s", strings.Join(errorStrings, ", "))
+	}
+	return report, nil
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForgetVisitor(t *testing.T) {
+	cacheCampaignsVisitors := map[string]map[string]*cache.CampaignCache{
+		"anonymous": {caID: &cache.CampaignCache{VariationGroupID: vgID}},
+		"logged":    {caID: &cache.CampaignCache{VariationGroupID: vgID}},
+		"other":     {caID: &cache.CampaignCache{VariationGroupID: vgID}},
+	}
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(
+		WithDecisionCache(decision.CacheOptions{}),
+		WithVisitorCache(cache.WithCustomOptions(cache.CustomOptions{
+			Getter: func(visitorID string) (map[string]*cache.CampaignCache, error) {
+				return cacheCampaignsVisitors[visitorID], nil
+			},
+			Setter: func(visitorID string, cache map[string]*cache.CampaignCache) error {
+				cacheCampaignsVisitors[visitorID] = cache
+				return nil
+			},
+			Deleter: func(visitorID string) error {
+				delete(cacheCampaignsVisitors, visitorID)
+				return nil
+			},
+		})),
+	)
+	client, _ := Create(options)
+	client.decisionClient = decision.NewCachedClient(createMockClient(), decision.CacheOptions{})
+
+	_, err := client.ForgetVisitor("")
+	assert.NotNil(t, err)
+
+	visitor, _ := client.NewVisitor("anonymous", model.Context{})
+	_ = visitor.Authenticate("logged", nil, true)
+	other, _ := client.NewVisitor("other", model.Context{})
+	_ = other.SynchronizeModifications()
+
+	report, err := client.ForgetVisitor("logged")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"logged", "anonymous"}, report.VisitorIDs)
+	assert.Equal(t, []string{"logged", "anonymous"}, report.CacheEntries)
+	assert.Equal(t, 1, report.DecisionCacheEntries)
+	assert.Equal(t, 1, len(cacheCampaignsVisitors))
+	assert.NotNil(t, cacheCampaignsVisitors["other"])
+
+	report, err = client.ForgetVisitor("unknown")
+	assert.Nil(t, err)
+	assert.Equal(t, ForgetReport{VisitorIDs: []string{"unknown"}}, report)
+}
+
+func TestForgetVisitorNotHandled(t *testing.T) {
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithVisitorCache(cache.WithCustomOptions(cache.CustomOptions{
+		Getter: func(visitorID string) (map[string]*cache.CampaignCache, error) { return nil, nil },
+		Setter: func(visitorID string, cache map[string]*cache.CampaignCache) error { return nil },
+	})))
+	client, _ := Create(options)
+
+	report, err := client.ForgetVisitor(testVID)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{testVID}, report.VisitorIDs)
+}
+
+func TestForgetVisitorReconciled(t *testing.T) {
+	cacheCampaignsVisitors := map[string]map[string]*cache.CampaignCache{
+		"anonymous": {caID: &cache.CampaignCache{VariationGroupID: vgID}},
+		"logged":    {caID: &cache.CampaignCache{VariationGroupID: vgID, AnonymousID: "anonymous"}},
+	}
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithVisitorCache(cache.WithCustomOptions(cache.CustomOptions{
+		Getter: func(visitorID string) (map[string]*cache.CampaignCache, error) {
+			return cacheCampaignsVisitors[visitorID], nil
+		},
+		Setter: func(visitorID string, cache map[string]*cache.CampaignCache) error {
+			cacheCampaignsVisitors[visitorID] = cache
+			return nil
+		},
+		Deleter: func(visitorID string) error {
+			delete(cacheCampaignsVisitors, visitorID)
+			return nil
+		},
+	})))
+	client, _ := Create(options)
+
+	// The cached assignments link the authenticated visitor to the anonymous one
+	report, err := client.ForgetVisitor("logged")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"logged", "anonymous"}, report.VisitorIDs)
+	assert.Equal(t, []string{"logged", "anonymous"}, report.CacheEntries)
+	assert.Equal(t, 0, len(cacheCampaignsVisitors))
+}
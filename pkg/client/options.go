@@ -1,21 +1,36 @@
 package client
 
 import (
+	"time"
+
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/bucketing"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decisionapi"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/tracking"
 )
 
 // Options represent the options passed to the Flagship SDK client
 type Options struct {
-	EnvID               string
-	APIKey              string
-	decisionMode        DecisionMode
-	bucketingOptions    []func(*bucketing.Engine)
-	decisionAPIOptions  []func(*decisionapi.APIClient)
-	cacheManagerOptions []cache.OptionBuilder
-	trackingAPIClient   tracking.APIClientInterface
+	EnvID                string
+	APIKey               string
+	decisionMode         DecisionMode
+	bucketingOptions     []func(*bucketing.Engine)
+	decisionAPIOptions   []func(*decisionapi.APIClient)
+	cacheManagerOptions  []cache.OptionBuilder
+	trackingAPIClient    tracking.APIClientInterface
+	hybridOptions        decision.HybridOptions
+	shadowOptions        *decision.ShadowOptions
+	shadowEngineOptions  []func(*bucketing.Engine)
+	decisionCacheOptions *decision.CacheOptions
+	requestCoalescing    bool
+	bulkConcurrency      int
+	visitorIDGenerator   func() string
+	defaultContext       model.Context
+	sessionTracking      bool
+	sessionTimeout       time.Duration
+	maxGetterWait        time.Duration
 }
 
 // OptionBuilder is a func type to set options to the FlagshipOption.
@@ -39,6 +54,16 @@ func WithBucketing(options ...func(*bucketing.Engine)) OptionBuilder {
 	}
 }
 
+// WithHybrid enables the hybrid decision mode for the SDK: decisions come from the Decision API,
+// and from a bucketing engine built with the engine options when the Decision API fails or its circuit is open
+func WithHybrid(hybridOptions decision.HybridOptions, options ...func(*bucketing.Engine)) OptionBuilder {
+	return func(f *Options) {
+		f.decisionMode = Hybrid
+		f.hybridOptions = hybridOptions
+		f.bucketingOptions = options
+	}
+}
+
 // WithDecisionAPI changes the decision API options
 func WithDecisionAPI(options ...func(*decisionapi.APIClient)) OptionBuilder {
 	return func(f *Options) {
@@ -59,3 +84,70 @@ func WithTrackingAPIClient(trackingAPIClient tracking.APIClientInterface) Option
 		f.trackingAPIClient = trackingAPIClient
 	}
 }
+
+// WithShadowComparison compares the served decisions with the other decision mode for a sampled fraction of visitors.
+// In API mode, decisions are compared with a bucketing engine built with the engine options, and in bucketing mode with the Decision API.
+// The shadow comparison is not available in hybrid mode. Dispose waits for the running comparisons
+func WithShadowComparison(options decision.ShadowOptions, engineOptions ...func(*bucketing.Engine)) OptionBuilder {
+	return func(f *Options) {
+		f.shadowOptions = &options
+		f.shadowEngineOptions = engineOptions
+	}
+}
+
+// WithDecisionCache caches in memory the decision responses by visitor ID, anonymous ID and context
+func WithDecisionCache(options decision.CacheOptions) OptionBuilder {
+	return func(f *Options) {
+		f.decisionCacheOptions = &options
+	}
+}
+
+// WithRequestCoalescing shares a single decision call between concurrent identical requests of the same visitor and context
+func WithRequestCoalescing() OptionBuilder {
+	return func(f *Options) {
+		f.requestCoalescing = true
+	}
+}
+
+// WithBulkConcurrency sets the number of decisions computed in parallel by GetDecisions.
+// Defaults to 10 against the Decision API and to the number of CPUs in bucketing mode
+func WithBulkConcurrency(concurrency int) OptionBuilder {
+	return func(f *Options) {
+		f.bulkConcurrency = concurrency
+	}
+}
+
+// WithVisitorIDGenerator sets the function generating the IDs of the visitors created without ID, and the anonymous IDs.
+// Defaults to random UUIDs
+func WithVisitorIDGenerator(generator func() string) OptionBuilder {
+	return func(f *Options) {
+		f.visitorIDGenerator = generator
+	}
+}
+
+// WithDefaultContext sets the context keys added to the context of every visitor.
+// The visitor context keys take precedence over the default context keys, which take precedence over the predefined context keys
+func WithDefaultContext(context model.Context) OptionBuilder {
+	return func(f *Options) {
+		f.defaultContext = context
+	}
+}
+
+// WithSessionTracking assigns sessions to the visitors, and sets the current session timestamp and the session number of their hits.
+// A new session starts after an inactivity longer than the timeout, which defaults to 30 minutes.
+// The sessions are saved through the visitor cache when its manager handles them
+func WithSessionTracking(timeout time.Duration) OptionBuilder {
+	return func(f *Options) {
+		f.sessionTracking = true
+		f.sessionTimeout = timeout
+	}
+}
+
+// WithMaxGetterWait sets the maximum duration the modification getters wait for an in-flight asynchronous synchronization,
+// independently of the synchronization context. Past this duration, the getters return the default values of the flags not yet fetched.
+// Defaults to 0, waiting until the synchronization is over or its context is done
+func WithMaxGetterWait(wait time.Duration) OptionBuilder {
+	return func(f *Options) {
+		f.maxGetterWait = wait
+	}
+}
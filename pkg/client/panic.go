@@ -0,0 +1,36 @@
+package client
+
+import (
+	"sync/atomic"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+// panicMode tracks whether the Decision API reports the environment in panic mode.
+// While the panic mode is on, the flags are served with their default values and no activation nor hit is sent
+type panicMode struct {
+	enabled int32
+}
+
+// isEnabled returns true if the environment is in panic mode
+func (p *panicMode) isEnabled() bool {
+	return p != nil && atomic.LoadInt32(&p.enabled) == 1
+}
+
+// update sets the panic mode from a decision response. Only the Decision API responses report the panic mode
+func (p *panicMode) update(resp *model.APIClientResponse) {
+	if p == nil || resp == nil || resp.Source != model.SOURCE_DECISION_API {
+		return
+	}
+
+	if resp.Panic {
+		if atomic.CompareAndSwapInt32(&p.enabled, 0, 1) {
+			clientLogger.Warn("Environment is in panic mode. Flags default values are served and no hit is sent")
+		}
+		return
+	}
+
+	if atomic.CompareAndSwapInt32(&p.enabled, 1, 0) {
+		clientLogger.Info("Environment is not in panic mode anymore")
+	}
+}
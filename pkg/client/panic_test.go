@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func createPanicMockClient(panic bool) decision.ClientInterface {
+	return decision.NewAPIClientMock(testEnvID, &model.APIClientResponse{
+		VisitorID: testVID,
+		Panic:     panic,
+		Source:    model.SOURCE_DECISION_API,
+		Campaigns: []model.Campaign{
+			{
+				ID:               caID,
+				VariationGroupID: vgID,
+				Variation: model.ClientVariation{
+					ID: testVID,
+					Modifications: model.Modification{
+						Type:  "FLAG",
+						Value: map[string]interface{}{"test_bool": true},
+					},
+				},
+			},
+		},
+	}, 200)
+}
+
+func TestPanicMode(t *testing.T) {
+	client := createClient()
+	client.decisionClient = createPanicMockClient(true)
+	trackingAPIClient := &countingTrackingAPIClient{}
+	client.trackingAPIClient = trackingAPIClient
+	assert.False(t, client.IsPanic())
+
+	visitor, _ := client.NewVisitor(testVID, nil)
+	assert.Nil(t, visitor.SynchronizeModifications())
+	assert.True(t, client.IsPanic())
+	assert.Equal(t, STATUS_PANIC, client.GetStatus())
+
+	// Default values are served and no activation nor hit is sent
+	value, err := visitor.GetModificationBool("test_bool", false, true)
+	assert.Nil(t, err)
+	assert.False(t, value)
+	assert.Nil(t, visitor.ActivateModification("test_bool"))
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	assert.Nil(t, client.SendHit(testVID, nil, &model.EventHit{Action: "action"}))
+	assert.Equal(t, int64(0), trackingAPIClient.activations)
+	assert.Equal(t, int64(0), trackingAPIClient.hits)
+
+	flagSet, _ := client.Evaluate(context.Background(), testVID, nil, EvaluateOptions{})
+	assert.False(t, flagSet.Has("test_bool"))
+
+	// Panic mode is left as soon as the Decision API reports the normal mode
+	client.decisionClient = createPanicMockClient(false)
+	visitor.decisionClient = client.decisionClient
+	assert.Nil(t, visitor.SynchronizeModifications())
+	assert.False(t, client.IsPanic())
+	assert.Equal(t, STATUS_READY, client.GetStatus())
+
+	value, _ = visitor.GetModificationBool("test_bool", false, true)
+	assert.True(t, value)
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	assert.Equal(t, int64(1), trackingAPIClient.activations)
+	assert.Equal(t, int64(1), trackingAPIClient.hits)
+}
+
+func TestPanicModeSource(t *testing.T) {
+	mode := &panicMode{}
+	mode.update(&model.APIClientResponse{Panic: true, Source: model.SOURCE_CACHE})
+	assert.False(t, mode.isEnabled())
+
+	mode.update(&model.APIClientResponse{Panic: true, Source: model.SOURCE_DECISION_API})
+	assert.True(t, mode.isEnabled())
+
+	mode.update(&model.APIClientResponse{Source: model.SOURCE_BUCKETING})
+	assert.True(t, mode.isEnabled())
+
+	var nilMode *panicMode
+	nilMode.update(&model.APIClientResponse{Panic: true, Source: model.SOURCE_DECISION_API})
+	assert.False(t, nilMode.isEnabled())
+}
+
+func TestPanicModeSyncedVisitor(t *testing.T) {
+	client := createClient()
+	client.decisionClient = createPanicMockClient(false)
+	trackingAPIClient := &countingTrackingAPIClient{}
+	client.trackingAPIClient = trackingAPIClient
+
+	// The visitor is synchronized before the panic mode is enabled by another visitor
+	visitor, _ := client.NewVisitor(testVID, nil)
+	assert.Nil(t, visitor.SynchronizeModifications())
+	value, _ := visitor.GetModificationBool("test_bool", false, false)
+	assert.True(t, value)
+
+	client.decisionClient = createPanicMockClient(true)
+	otherVisitor, _ := client.NewVisitor("other", nil)
+	assert.Nil(t, otherVisitor.SynchronizeModifications())
+	assert.True(t, client.IsPanic())
+
+	value, err := visitor.GetModificationBool("test_bool", false, true)
+	assert.Nil(t, err)
+	assert.False(t, value)
+	assert.Equal(t, map[string]model.FlagInfos{}, visitor.GetAllModifications())
+	info, err := visitor.GetModificationInfo("test_bool")
+	assert.Nil(t, err)
+	assert.Nil(t, info)
+	assert.Equal(t, int64(0), trackingAPIClient.activations)
+}
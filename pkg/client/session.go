@@ -0,0 +1,121 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+// DEFAULT_SESSION_TIMEOUT is the default inactivity duration after which a new session starts
+const DEFAULT_SESSION_TIMEOUT = 30 * time.Minute
+
+// sessionTracker assigns the sessions of the visitors. The sessions are saved through the cache manager when it handles them,
+// and the active sessions are kept in memory
+type sessionTracker struct {
+	timeout      time.Duration
+	cacheManager cache.Manager
+	sessions     map[string]cache.SessionCache
+	lastPrune    time.Time
+	mu           sync.Mutex
+	now          func() time.Time
+}
+
+func newSessionTracker(timeout time.Duration, cacheManager cache.Manager) *sessionTracker {
+	if timeout <= 0 {
+		timeout = DEFAULT_SESSION_TIMEOUT
+	}
+	return &sessionTracker{
+		timeout:      timeout,
+		cacheManager: cacheManager,
+		sessions:     map[string]cache.SessionCache{},
+		now:          time.Now,
+	}
+}
+
+// hasSessionStore returns true if the sessions are saved through the cache manager
+func (t *sessionTracker) hasSessionStore() bool {
+	return cache.HandlesSessions(t.cacheManager)
+}
+
+// track registers an activity of the visitor, and returns its current session
+func (t *sessionTracker) track(visitorID string) cache.SessionCache {
+	t.mu.Lock()
+	session, ok := t.sessions[visitorID]
+	t.mu.Unlock()
+
+	// The session is loaded from the cache manager when it is not active in memory
+	if !ok && t.hasSessionStore() {
+		saved, err := cache.GetSession(t.cacheManager, visitorID)
+		if err != nil {
+			clientLogger.Warn(fmt.Sprintf("Error when getting session of visitor %s from cache: ", visitorID), err)
+		} else if saved != nil {
+			session = *saved
+		}
+	}
+
+	t.mu.Lock()
+	if active, ok := t.sessions[visitorID]; ok {
+		session = active
+	}
+
+	now := t.now()
+	if session.SessionNumber == 0 || now.Sub(session.LastActivity) > t.timeout {
+		session.SessionNumber++
+		session.SessionTimestamp = now.Unix()
+	}
+	session.LastActivity = now
+	t.sessions[visitorID] = session
+	t.prune(now)
+	t.mu.Unlock()
+
+	if t.hasSessionStore() {
+		saved := session
+		if err := cache.SetSession(t.cacheManager, visitorID, &saved, t.timeout); err != nil {
+			clientLogger.Warn(fmt.Sprintf("Error when saving session of visitor %s in cache: ", visitorID), err)
+		}
+	}
+	return session
+}
+
+// prune removes the expired sessions from memory. Without cache manager handling sessions,
+// the sessions of a visitor are counted again from 1 after an inactivity longer than the timeout
+func (t *sessionTracker) prune(now time.Time) {
+	if now.Sub(t.lastPrune) < t.timeout {
+		return
+	}
+	t.lastPrune = now
+	for visitorID, session := range t.sessions {
+		if now.Sub(session.LastActivity) > t.timeout {
+			delete(t.sessions, visitorID)
+		}
+	}
+}
+
+// forget removes the session of the visitor from memory
+func (t *sessionTracker) forget(visitorID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sessions, visitorID)
+}
+
+// stamp sets the session of the visitor to the hit. Consent hits are not part of the visitor activity and are not stamped
+func (t *sessionTracker) stamp(visitorID string, anonymousID *string, hit model.HitInterface) {
+	if t == nil || model.IsConsentHit(hit) {
+		return
+	}
+
+	sessionHit, ok := hit.(model.SessionHitInterface)
+	if !ok {
+		return
+	}
+
+	// The session follows the anonymous ID, which is the visitor ID of the hits, so it continues after authentication
+	if anonymousID != nil {
+		visitorID = *anonymousID
+	}
+	session := t.track(visitorID)
+	sessionHit.SetSessionInfos(session.SessionTimestamp, session.SessionNumber)
+}
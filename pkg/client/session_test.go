@@ -0,0 +1,166 @@
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTrackingAPIClient struct {
+	FakeTrackingAPIClient
+	mu   sync.Mutex
+	hits []model.HitInterface
+}
+
+func (c *recordingTrackingAPIClient) SendHit(visitorID string, anonymousID *string, hit model.HitInterface) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits = append(c.hits, hit)
+	return nil
+}
+
+func (c *recordingTrackingAPIClient) lastHit() *model.EventHit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits[len(c.hits)-1].(*model.EventHit)
+}
+
+func createSessionClient(sessions map[string]*cache.SessionCache) *Client {
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(
+		WithSessionTracking(0),
+		WithVisitorCache(cache.WithCustomOptions(cache.CustomOptions{
+			Getter: func(visitorID string) (map[string]*cache.CampaignCache, error) { return nil, nil },
+			Setter: func(visitorID string, cache map[string]*cache.CampaignCache) error { return nil },
+			SessionGetter: func(visitorID string) (*cache.SessionCache, error) {
+				return sessions[visitorID], nil
+			},
+			SessionSetter: func(visitorID string, session *cache.SessionCache, timeout time.Duration) error {
+				sessions[visitorID] = session
+				return nil
+			},
+		})),
+	)
+	client, _ := Create(options)
+	client.decisionClient = createMockClient()
+	client.trackingAPIClient = &recordingTrackingAPIClient{}
+	return client
+}
+
+func TestSessionTracking(t *testing.T) {
+	sessions := map[string]*cache.SessionCache{}
+	client := createSessionClient(sessions)
+	trackingAPIClient := client.trackingAPIClient.(*recordingTrackingAPIClient)
+	assert.Equal(t, DEFAULT_SESSION_TIMEOUT, client.sessionTracker.timeout)
+
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	now := start
+	client.sessionTracker.now = func() time.Time { return now }
+
+	visitor, _ := client.NewVisitor(testVID, model.Context{})
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	assert.Equal(t, start.Unix(), trackingAPIClient.lastHit().CurrentSessionTimestamp)
+	assert.Equal(t, int64(1), trackingAPIClient.lastHit().SessionNumber)
+	assert.Equal(t, int64(1), sessions[testVID].SessionNumber)
+
+	// The session continues while the visitor is active
+	now = now.Add(20 * time.Minute)
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	now = now.Add(20 * time.Minute)
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	assert.Equal(t, start.Unix(), trackingAPIClient.lastHit().CurrentSessionTimestamp)
+	assert.Equal(t, int64(1), trackingAPIClient.lastHit().SessionNumber)
+
+	// A new session starts after the inactivity timeout
+	now = now.Add(31 * time.Minute)
+	assert.Nil(t, client.SendHit(testVID, nil, &model.EventHit{Action: "action"}))
+	assert.Equal(t, now.Unix(), trackingAPIClient.lastHit().CurrentSessionTimestamp)
+	assert.Equal(t, int64(2), trackingAPIClient.lastHit().SessionNumber)
+
+	// The values set on the hit are kept
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action", BaseHit: model.BaseHit{SessionNumber: 7}}))
+	assert.Equal(t, now.Unix(), trackingAPIClient.lastHit().CurrentSessionTimestamp)
+	assert.Equal(t, int64(7), trackingAPIClient.lastHit().SessionNumber)
+
+	// The session is loaded from the cache by another client
+	otherClient := createSessionClient(sessions)
+	otherTrackingAPIClient := otherClient.trackingAPIClient.(*recordingTrackingAPIClient)
+	otherClient.sessionTracker.now = func() time.Time { return now.Add(time.Minute) }
+	assert.Nil(t, otherClient.SendHit(testVID, nil, &model.EventHit{Action: "action"}))
+	assert.Equal(t, now.Unix(), otherTrackingAPIClient.lastHit().CurrentSessionTimestamp)
+	assert.Equal(t, int64(2), otherTrackingAPIClient.lastHit().SessionNumber)
+
+	// The session follows the anonymous ID after authentication
+	assert.Nil(t, visitor.Authenticate("logged", nil, false))
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	assert.Equal(t, int64(2), trackingAPIClient.lastHit().SessionNumber)
+	assert.Nil(t, sessions["logged"])
+
+	_, _ = client.ForgetVisitor(testVID)
+	_, ok := client.sessionTracker.sessions[testVID]
+	assert.False(t, ok)
+}
+
+func TestSessionTrackingConsent(t *testing.T) {
+	sessions := map[string]*cache.SessionCache{}
+	client := createSessionClient(sessions)
+	trackingAPIClient := client.trackingAPIClient.(*recordingTrackingAPIClient)
+
+	// No session is tracked nor saved for a visitor who declined tracking
+	visitor, _ := client.NewVisitor(testVID, model.Context{}, WithConsent(false))
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	assert.Nil(t, visitor.SetConsent(false))
+	assert.Equal(t, 1, len(trackingAPIClient.hits))
+	assert.Equal(t, int64(0), trackingAPIClient.lastHit().SessionNumber)
+	assert.Equal(t, 0, len(sessions))
+	assert.Equal(t, 0, len(client.sessionTracker.sessions))
+
+	// Consent hits are not stamped
+	assert.Nil(t, visitor.SetConsent(true))
+	assert.Equal(t, int64(0), trackingAPIClient.lastHit().SessionNumber)
+	assert.Equal(t, 0, len(sessions))
+
+	assert.Nil(t, visitor.SendHit(&model.EventHit{Action: "action"}))
+	assert.Equal(t, int64(1), trackingAPIClient.lastHit().SessionNumber)
+	assert.Equal(t, 1, len(sessions))
+}
+
+func TestSessionTrackingWithoutCache(t *testing.T) {
+	client := createClient()
+	trackingAPIClient := &recordingTrackingAPIClient{}
+	client.trackingAPIClient = trackingAPIClient
+
+	// Hits are not stamped without session tracking
+	assert.Nil(t, client.SendHit(testVID, nil, &model.EventHit{Action: "action"}))
+	assert.Equal(t, int64(0), trackingAPIClient.lastHit().SessionNumber)
+
+	// A custom cache manager without session getter and setter does not save the sessions
+	customManager, _ := cache.InitManager(cache.WithCustomOptions(cache.CustomOptions{
+		Getter: func(visitorID string) (map[string]*cache.CampaignCache, error) { return nil, nil },
+		Setter: func(visitorID string, cache map[string]*cache.CampaignCache) error { return nil },
+	}))
+	assert.False(t, newSessionTracker(time.Minute, customManager).hasSessionStore())
+
+	client.sessionTracker = newSessionTracker(time.Minute, nil)
+	now := time.Now()
+	client.sessionTracker.now = func() time.Time { return now }
+
+	assert.Nil(t, client.SendHit(testVID, nil, &model.EventHit{Action: "action"}))
+	assert.Equal(t, int64(1), trackingAPIClient.lastHit().SessionNumber)
+
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, client.SendHit(testVID, nil, &model.EventHit{Action: "action"}))
+	assert.Equal(t, int64(2), trackingAPIClient.lastHit().SessionNumber)
+
+	// The expired sessions are removed from memory
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, client.SendHit("other", nil, &model.EventHit{Action: "action"}))
+	assert.Equal(t, 1, len(client.sessionTracker.sessions))
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// SyncFuture represents the result of an asynchronous synchronization of the visitor modifications
+type SyncFuture struct {
+	done chan struct{}
+	err  error
+}
+
+// resolve sets the synchronization result and releases the waiting goroutines
+func (f *SyncFuture) resolve(err error) {
+	f.err = err
+	close(f.done)
+}
+
+// Done returns a channel closed when the synchronization is over
+func (f *SyncFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the synchronization to be over and returns its error
+func (f *SyncFuture) Wait() error {
+	<-f.done
+	return f.err
+}
+
+// SynchronizeModificationsAsync starts the synchronization of the visitor modifications in the background.
+// The returned future is resolved when the synchronization is over, or with the context error when the context is done.
+// Until then, the modification getters wait for the synchronization before reading the flags.
+// A synchronization that completes after the context is done still updates the visitor flags
+func (v *Visitor) SynchronizeModificationsAsync(ctx context.Context) *SyncFuture {
+	future := &SyncFuture{
+		done: make(chan struct{}),
+	}
+
+	v.mu.Lock()
+	v.pendingSync = future
+	v.mu.Unlock()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- v.SynchronizeModifications()
+	}()
+
+	go func() {
+		select {
+		case err := <-result:
+			future.resolve(err)
+		case <-ctx.Done():
+			visitorLogger.Warnf("Synchronization of visitor %s not over before the context is done", v.GetID())
+			future.resolve(ctx.Err())
+		}
+	}()
+
+	return future
+}
+
+// waitPendingSync waits for the last asynchronous synchronization, if any, to be over, or for the maximum getter wait
+func (v *Visitor) waitPendingSync() {
+	v.mu.RLock()
+	future := v.pendingSync
+	v.mu.RUnlock()
+
+	if future == nil {
+		return
+	}
+
+	if v.maxGetterWait <= 0 {
+		<-future.Done()
+		return
+	}
+
+	timer := time.NewTimer(v.maxGetterWait)
+	defer timer.Stop()
+	select {
+	case <-future.Done():
+	case <-timer.C:
+		visitorLogger.Warnf("Synchronization of visitor %s not over after %v. Reading the current flags", v.GetID(), v.maxGetterWait)
+	}
+}
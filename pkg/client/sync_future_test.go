@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSynchronizeModificationsAsync(t *testing.T) {
+	visitor := createVisitor("test", nil)
+	blockingClient := &blockingDecisionClient{
+		ClientInterface: visitor.decisionClient,
+		started:         make(chan struct{}, 1),
+		release:         make(chan struct{}),
+	}
+	visitor.decisionClient = blockingClient
+
+	future := visitor.SynchronizeModificationsAsync(context.Background())
+	<-blockingClient.started
+
+	select {
+	case <-future.Done():
+		t.Error("Future should not be resolved before the synchronization is over")
+	default:
+	}
+
+	// Getters wait for the in-flight synchronization
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(blockingClient.release)
+	}()
+	value, err := visitor.GetModificationString("test_string", "default", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "string", value)
+
+	assert.Nil(t, future.Wait())
+	assert.Equal(t, FETCH_STATUS_FETCHED, visitor.GetFetchStatus().Status)
+}
+
+func TestSynchronizeModificationsAsyncError(t *testing.T) {
+	visitor := createVisitor("test", nil)
+	visitor.decisionClient = decision.NewAPIClientMock(testEnvID, nil, 500)
+
+	future := visitor.SynchronizeModificationsAsync(context.Background())
+	assert.NotNil(t, future.Wait())
+
+	value, err := visitor.GetModificationString("test_string", "default", false)
+	assert.NotNil(t, err)
+	assert.Equal(t, "default", value)
+}
+
+func TestSynchronizeModificationsAsyncDeadline(t *testing.T) {
+	visitor := createVisitor("test", nil)
+	blockingClient := &blockingDecisionClient{
+		ClientInterface: visitor.decisionClient,
+		started:         make(chan struct{}, 1),
+		release:         make(chan struct{}),
+	}
+	visitor.decisionClient = blockingClient
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	future := visitor.SynchronizeModificationsAsync(ctx)
+
+	// Getters fall back to the default value once the deadline is exceeded
+	value, err := visitor.GetModificationString("test_string", "default", false)
+	assert.NotNil(t, err)
+	assert.Equal(t, "default", value)
+	assert.Equal(t, context.DeadlineExceeded, future.Wait())
+
+	// The late synchronization still updates the flags
+	close(blockingClient.release)
+	assert.Eventually(t, func() bool {
+		return visitor.GetFetchStatus().Status == FETCH_STATUS_FETCHED
+	}, time.Second, time.Millisecond)
+	value, _ = visitor.GetModificationString("test_string", "default", false)
+	assert.Equal(t, "string", value)
+}
+
+func TestSynchronizeModificationsAsyncMaxGetterWait(t *testing.T) {
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	options.BuildOptions(WithMaxGetterWait(20 * time.Millisecond))
+	client, _ := Create(options)
+	blockingClient := &blockingDecisionClient{
+		ClientInterface: createMockClient(),
+		started:         make(chan struct{}, 1),
+		release:         make(chan struct{}),
+	}
+	client.decisionClient = blockingClient
+	client.trackingAPIClient = &FakeTrackingAPIClient{}
+	visitor, _ := client.NewVisitor("test", nil)
+	assert.Equal(t, 20*time.Millisecond, visitor.maxGetterWait)
+
+	future := visitor.SynchronizeModificationsAsync(context.Background())
+	<-blockingClient.started
+
+	// Getters stop waiting after the maximum getter wait, even without context deadline
+	start := time.Now()
+	value, err := visitor.GetModificationString("test_string", "default", false)
+	assert.NotNil(t, err)
+	assert.Equal(t, "default", value)
+	assert.True(t, time.Since(start) < time.Second)
+
+	select {
+	case <-future.Done():
+		t.Error("Future should not be resolved before the synchronization is over")
+	default:
+	}
+
+	close(blockingClient.release)
+	assert.Nil(t, future.Wait())
+	value, _ = visitor.GetModificationString("test_string", "default", false)
+	assert.Equal(t, "string", value)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
@@ -16,17 +17,41 @@ import (
 
 var visitorLogger = logging.CreateLogger("FS Visitor")
 
-// Visitor represents a visitor instance of the Flagship SDK
+// Visitor represents a visitor instance of the Flagship SDK.
+// Its methods are safe for concurrent use. The ID, AnonymousID and Context fields must not be modified directly,
+// and should be read with GetID, GetAnonymousID and GetContext when the visitor is shared between goroutines
 type Visitor struct {
-	ID                string
-	AnonymousID       *string
-	Context           model.Context
-	decisionClient    decision.ClientInterface
-	decisionMode      DecisionMode
-	decisionResponse  *model.APIClientResponse
-	flagInfos         map[string]model.FlagInfos
-	trackingAPIClient tracking.APIClientInterface
-	cacheManager      cache.Manager
+	ID                 string
+	AnonymousID        *string
+	Context            model.Context
+	baseContext        model.Context
+	envID              string
+	activatedCampaigns map[string]bool
+	hasConsented       bool
+	decisionClient     decision.ClientInterface
+	decisionMode       DecisionMode
+	decisionResponse   *model.APIClientResponse
+	flagInfos          map[string]model.FlagInfos
+	trackingAPIClient  tracking.APIClientInterface
+	cacheManager       cache.Manager
+	sessionTracker     *sessionTracker
+	fetchStatus        FetchFlagsStatus
+	fetchRequests      uint64
+	configVersion      int64
+	pendingSync        *SyncFuture
+	maxGetterWait      time.Duration
+	panicMode          *panicMode
+	mu                 sync.RWMutex
+}
+
+// visitorState represents the visitor state at a given time.
+// The context and flag infos maps are replaced and never modified, so the snapshot can be read without lock
+type visitorState struct {
+	id           string
+	anonymousID  *string
+	context      model.Context
+	flagInfos    map[string]model.FlagInfos
+	hasConsented bool
 }
 
 // ModificationInfo represents additional info linked to the modification key, for third party services
@@ -38,9 +63,51 @@ type ModificationInfo struct {
 	Value            interface{}
 }
 
-func generateAnonymousID() string {
-	newID := time.Now().Format("20060102030405.000000")
-	return newID[:len(newID)-1]
+// copyContext returns a shallow copy of the context
+func copyContext(context model.Context) model.Context {
+	copied := make(model.Context, len(context))
+	for k, val := range context {
+		copied[k] = val
+	}
+	return copied
+}
+
+// getState returns the current visitor state
+func (v *Visitor) getState() visitorState {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.getStateLocked()
+}
+
+// getStateLocked returns the current visitor state. It must be called with the visitor lock held
+func (v *Visitor) getStateLocked() visitorState {
+	return visitorState{
+		id:           v.ID,
+		anonymousID:  v.AnonymousID,
+		context:      v.Context,
+		flagInfos:    v.flagInfos,
+		hasConsented: v.hasConsented,
+	}
+}
+
+// GetID returns the visitor ID
+func (v *Visitor) GetID() string {
+	return v.getState().id
+}
+
+// GetAnonymousID returns a copy of the visitor anonymous ID, or nil if the visitor is not authenticated
+func (v *Visitor) GetAnonymousID() *string {
+	anonymousID := v.getState().anonymousID
+	if anonymousID == nil {
+		return nil
+	}
+	anonID := *anonymousID
+	return &anonID
+}
+
+// GetContext returns a copy of the visitor context
+func (v *Visitor) GetContext() model.Context {
+	return copyContext(v.getState().context)
 }
 
 // UpdateContext updates the Visitor context with new value
@@ -61,6 +128,14 @@ func (v *Visitor) UpdateContext(newContext model.Context) (err error) {
 		return fmt.Errorf("Invalid context : %s", strings.Join(errorStrings, ", "))
 	}
 
+	newContext = mergeContexts(v.baseContext, newContext)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.isContextChangeRelevant(newContext) {
+		v.requireFetch(FETCH_REASON_CONTEXT_CHANGED)
+	}
 	v.Context = newContext
 	return nil
 }
@@ -73,14 +148,12 @@ func (v *Visitor) UpdateContextKey(key string, value interface{}) (err error) {
 		}
 	}()
 
-	newContext := model.Context{}
-	for k, v := range v.Context {
-		newContext[k] = v
-	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
 
-	newContext[key] = value
-
-	errs := newContext.Validate()
+	// Only the new key is validated, as the context contains the reserved keys set by the SDK
+	keyContext := model.Context{key: value}
+	errs := keyContext.Validate()
 	if len(errs) > 0 {
 		errorStrings := []string{}
 		for _, e := range errs {
@@ -90,17 +163,29 @@ func (v *Visitor) UpdateContextKey(key string, value interface{}) (err error) {
 		return fmt.Errorf("Invalid context : %s", strings.Join(errorStrings, ", "))
 	}
 
+	newContext := copyContext(v.Context)
+	newContext[key] = keyContext[key]
+
+	if v.isContextChangeRelevant(newContext) {
+		v.requireFetch(FETCH_REASON_CONTEXT_CHANGED)
+	}
 	v.Context = newContext
 	return nil
 }
 
 // Authenticate set the authenticated ID for the visitor, along with optional new context and re-synchronize flag
 func (v *Visitor) Authenticate(newID string, newContext map[string]interface{}, sync bool) (err error) {
+	v.mu.Lock()
 	if v.AnonymousID == nil {
 		anonID := v.ID
 		v.AnonymousID = &anonID
 	}
 	v.ID = newID
+	// The activated campaigns are recorded per visitor ID
+	v.activatedCampaigns = nil
+	v.requireFetch(FETCH_REASON_AUTHENTICATED)
+	v.mu.Unlock()
+
 	if newContext != nil {
 		err = v.UpdateContext(newContext)
 		if err != nil {
@@ -115,10 +200,14 @@ func (v *Visitor) Authenticate(newID string, newContext map[string]interface{},
 
 // Unauthenticate unset the authenticated ID for the visitor
 func (v *Visitor) Unauthenticate(newContext map[string]interface{}, sync bool) (err error) {
+	v.mu.Lock()
 	if v.AnonymousID != nil {
 		v.ID = *v.AnonymousID
 		v.AnonymousID = nil
+		v.activatedCampaigns = nil
+		v.requireFetch(FETCH_REASON_UNAUTHENTICATED)
 	}
+	v.mu.Unlock()
 
 	if newContext != nil {
 		err = v.UpdateContext(newContext)
@@ -140,41 +229,68 @@ func (v *Visitor) SynchronizeModifications() (err error) {
 		}
 	}()
 
-	if v.ID == "" {
+	v.mu.RLock()
+	state := v.getStateLocked()
+	fetchRequests := v.fetchRequests
+	v.mu.RUnlock()
+
+	if state.id == "" {
 		err := errors.New("Visitor ID should not be empty")
 		visitorLogger.Error("Visitor ID is not set", err)
 		return err
 	}
 
-	visitorLogger.Info(fmt.Sprintf("Getting modifications for visitor with id : %s", v.ID))
-	resp, err := v.decisionClient.GetModifications(v.ID, v.AnonymousID, v.Context)
+	// The configuration version is read before the decision, so that a configuration loaded during the decision requires a new fetch
+	targetingInfo, hasTargetingInfo := v.decisionClient.(decision.TargetingInfoInterface)
+	var configVersion int64
+	if hasTargetingInfo {
+		configVersion = targetingInfo.GetConfigVersion()
+	}
+
+	visitorLogger.Info(fmt.Sprintf("Getting modifications for visitor with id : %s", state.id))
+	resp, err := decision.GetModificationsWithConsent(v.decisionClient, state.id, state.anonymousID, state.context, state.hasConsented)
 
 	if err != nil {
 		visitorLogger.Error("Error when calling Decision engine: ", err)
 		return err
 	}
+	v.panicMode.update(resp)
 
-	if v.trackingAPIClient != nil && v.decisionMode != API {
-		go func() {
-			visitorLogger.Info("Sending context info to event collect in the background")
-			err := v.trackingAPIClient.SendEvent(model.Event{
-				VisitorID: v.ID,
-				Type:      model.CONTEXT,
-				Data:      v.Context,
-			})
-			if err != nil {
-				visitorLogger.Warn("Error when sending context: ", err)
-			} else {
-				visitorLogger.Info("Context sent successfully")
-			}
-		}()
-	}
+	sendContextEvent(v.trackingAPIClient, v.decisionMode, resp, state.id, state.context, state.hasConsented)
+
+	visitorLogger.Info(fmt.Sprintf("Got %d campaign(s) for visitor with id : %s", len(resp.Campaigns), state.id))
+	flagInfos := getFlagInfos(resp)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
 
 	v.decisionResponse = resp
+	v.flagInfos = flagInfos
+	if hasTargetingInfo {
+		// A decision engine without configuration loads it for the decision
+		if configVersion == 0 {
+			configVersion = targetingInfo.GetConfigVersion()
+		}
+		v.configVersion = configVersion
+	}
 
-	flagInfos := map[string]model.FlagInfos{}
+	// The flags are still outdated if the visitor changed during the synchronization
+	if v.fetchRequests == fetchRequests {
+		v.fetchStatus = FetchFlagsStatus{
+			Status: FETCH_STATUS_FETCHED,
+			Reason: FETCH_REASON_NONE,
+		}
+	}
 
-	visitorLogger.Info(fmt.Sprintf("Got %d campaign(s) for visitor with id : %s", len(resp.Campaigns), v.ID))
+	return nil
+}
+
+// getFlagInfos returns the flag values of a decision response by flag key. No flag is set in panic mode
+func getFlagInfos(resp *model.APIClientResponse) map[string]model.FlagInfos {
+	flagInfos := map[string]model.FlagInfos{}
+	if resp.Panic {
+		return flagInfos
+	}
 	for _, c := range resp.Campaigns {
 		for k, val := range c.Variation.Modifications.Value {
 			flagInfos[k] = model.FlagInfos{
@@ -183,12 +299,10 @@ func (v *Visitor) SynchronizeModifications() (err error) {
 			}
 		}
 	}
-	v.flagInfos = flagInfos
-
-	return nil
+	return flagInfos
 }
 
-// getModification gets a flag value as interface{}
+// getModification gets a copy of a flag value as interface{}
 func (v *Visitor) getModification(key string, activate bool) (flagValue interface{}, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -196,14 +310,21 @@ func (v *Visitor) getModification(key string, activate bool) (flagValue interfac
 		}
 	}()
 
-	if v.flagInfos == nil {
+	v.waitPendingSync()
+	if v.panicMode.isEnabled() {
+		visitorLogger.Info("Environment is in panic mode. Fallback to default value")
+		return nil, nil
+	}
+
+	state := v.getState()
+	if state.flagInfos == nil {
 		err := errors.New("Visitor modifications have not been synchronized")
 		visitorLogger.Error("Visitor modifications are not set", err)
 
 		return nil, err
 	}
 
-	flagInfos, ok := v.flagInfos[key]
+	flagInfos, ok := state.flagInfos[key]
 
 	if !ok {
 		visitorLogger.Infof("key %s not set in decision infos.", key)
@@ -211,25 +332,50 @@ func (v *Visitor) getModification(key string, activate bool) (flagValue interfac
 	}
 
 	if activate {
-		err := v.activateModification(key)
+		err := v.activateModification(state, key)
 		if err != nil {
 			visitorLogger.Debug(fmt.Sprintf("Error occurred when activating campaign : %v.", err))
 		}
 	}
-	flagValue = flagInfos.Value
+	flagValue = copyFlagValue(flagInfos.Value)
 	return flagValue, nil
 }
 
-// GetAllModifications return all the modifications
+// GetAllModifications return a copy of all the modifications. No modification is returned in panic mode
 func (v *Visitor) GetAllModifications() (flagInfos map[string]model.FlagInfos) {
-	return v.flagInfos
+	v.waitPendingSync()
+	if v.panicMode.isEnabled() {
+		return map[string]model.FlagInfos{}
+	}
+
+	state := v.getState()
+	if state.flagInfos == nil {
+		return nil
+	}
+	flagInfos = make(map[string]model.FlagInfos, len(state.flagInfos))
+	for k, info := range state.flagInfos {
+		flagInfos[k] = info
+	}
+	return flagInfos
 }
 
 // GetDecisionResponse return the decision response
 func (v *Visitor) GetDecisionResponse() *model.APIClientResponse {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	return v.decisionResponse
 }
 
+// GetDecisionSource returns the source that computed the last decision response
+func (v *Visitor) GetDecisionSource() model.DecisionSource {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if v.decisionResponse == nil {
+		return ""
+	}
+	return v.decisionResponse.Source
+}
+
 // GetModificationBool get a modification bool by its key
 func (v *Visitor) GetModificationBool(key string, defaultValue bool, activate bool) (castVal bool, err error) {
 	defer func() {
@@ -388,14 +534,21 @@ func (v *Visitor) GetModificationInfo(key string) (modifInfo *ModificationInfo,
 		}
 	}()
 
-	if v.flagInfos == nil {
+	v.waitPendingSync()
+	if v.panicMode.isEnabled() {
+		visitorLogger.Info("Environment is in panic mode. No modification info is returned")
+		return nil, nil
+	}
+
+	state := v.getState()
+	if state.flagInfos == nil {
 		err := errors.New("Visitor modifications have not been synchronized")
 		visitorLogger.Error("Visitor modifications are not set", err)
 
 		return nil, err
 	}
 
-	flagInfos, ok := v.flagInfos[key]
+	flagInfos, ok := state.flagInfos[key]
 
 	if !ok {
 		visitorLogger.Infof("key %s not set in decision infos.", key)
@@ -407,36 +560,49 @@ func (v *Visitor) GetModificationInfo(key string) (modifInfo *ModificationInfo,
 		VariationGroupID: flagInfos.Campaign.VariationGroupID,
 		VariationID:      flagInfos.Campaign.Variation.ID,
 		IsReference:      flagInfos.Campaign.Variation.Reference,
-		Value:            flagInfos.Value,
+		Value:            copyFlagValue(flagInfos.Value),
 	}, nil
 }
 
-func (v *Visitor) activateModification(key string) error {
-	if v.flagInfos == nil {
+func (v *Visitor) activateModification(state visitorState, key string) error {
+	if state.flagInfos == nil {
 		err := errors.New("Visitor modifications have not been synchronized")
 		return err
 	}
 
-	flagInfos, ok := v.flagInfos[key]
+	flagInfos, ok := state.flagInfos[key]
 	if !ok {
 		visitorLogger.Infof("key %s not set in decision infos. Skipping", key)
 		return nil
 	}
 
-	visitorLogger.Info(fmt.Sprintf("Activating campaign for flag %s for visitor with id : %s", key, v.ID))
+	if v.panicMode.isEnabled() {
+		visitorLogger.Info("Environment is in panic mode. Skipping activation")
+		return nil
+	}
+
+	if !state.hasConsented {
+		visitorLogger.Info("Visitor has not consented to be tracked. Skipping activation")
+		return nil
+	}
+
+	visitorLogger.Info(fmt.Sprintf("Activating campaign for flag %s for visitor with id : %s", key, state.id))
 	err := v.trackingAPIClient.ActivateCampaign(model.ActivationHit{
 		VariationGroupID: flagInfos.Campaign.VariationGroupID,
 		VariationID:      flagInfos.Campaign.Variation.ID,
-		VisitorID:        v.ID,
-		AnonymousID:      v.AnonymousID,
+		VisitorID:        state.id,
+		AnonymousID:      state.anonymousID,
 	})
-	if err != nil && v.cacheManager != nil {
-		campaignsCache, err := v.cacheManager.Get(v.ID)
+	if err == nil {
+		v.setCampaignActivated(flagInfos.Campaign.ID)
+	}
+	if err != nil && v.cacheManager != nil && state.hasConsented {
+		campaignsCache, err := v.cacheManager.Get(state.id)
 		if err == nil {
 			existingCampaign, ok := campaignsCache[flagInfos.Campaign.ID]
 			if ok && !existingCampaign.Activated {
 				existingCampaign.Activated = true
-				err = v.cacheManager.Set(v.ID, campaignsCache)
+				err = v.cacheManager.Set(state.id, campaignsCache)
 			}
 		}
 		if err != nil {
@@ -446,6 +612,29 @@ func (v *Visitor) activateModification(key string) error {
 	return err
 }
 
+// sendContextEvent sends the visitor context to the event collect in the background when the flags were computed by the bucketing engine.
+// The context of the visitors who did not consent to be tracked is not sent
+func sendContextEvent(trackingAPIClient tracking.APIClientInterface, decisionMode DecisionMode, resp *model.APIClientResponse, visitorID string, context model.Context, hasConsented bool) {
+	isBucketingDecision := decisionMode == Bucketing || (decisionMode == Hybrid && resp.Source == model.SOURCE_BUCKETING)
+	if trackingAPIClient == nil || !isBucketingDecision || !hasConsented {
+		return
+	}
+
+	go func() {
+		visitorLogger.Info("Sending context info to event collect in the background")
+		err := trackingAPIClient.SendEvent(model.Event{
+			VisitorID: visitorID,
+			Type:      model.CONTEXT,
+			Data:      context,
+		})
+		if err != nil {
+			visitorLogger.Warn("Error when sending context: ", err)
+		} else {
+			visitorLogger.Info("Context sent successfully")
+		}
+	}()
+}
+
 // ActivateModification notifies Flagship that the visitor has seen to modification
 func (v *Visitor) ActivateModification(key string) (err error) {
 	defer func() {
@@ -454,28 +643,42 @@ func (v *Visitor) ActivateModification(key string) (err error) {
 		}
 	}()
 
-	err = v.activateModification(key)
+	err = v.activateModification(v.getState(), key)
 	return err
 }
 
 // ActivateCacheModification activates a modification from the cache of assigned visitor campaigns
 func (v *Visitor) ActivateCacheModification(key string) (err error) {
+	if v.panicMode.isEnabled() {
+		visitorLogger.Info("Environment is in panic mode. Skipping activation")
+		return nil
+	}
+
+	state := v.getState()
+	if !state.hasConsented {
+		visitorLogger.Info("Visitor has not consented to be tracked. Skipping activation")
+		return nil
+	}
+
 	if v.cacheManager != nil {
-		cacheCampaigns, err := v.cacheManager.Get(v.ID)
+		cacheCampaigns, err := v.cacheManager.Get(state.id)
 		if err != nil {
 			return err
 		}
 
-		for _, c := range cacheCampaigns {
+		for campaignID, c := range cacheCampaigns {
 			for _, k := range c.FlagKeys {
 				if k == key {
 					// Key found in cache. Activating it now
 					err = v.trackingAPIClient.ActivateCampaign(model.ActivationHit{
 						VariationGroupID: c.VariationGroupID,
 						VariationID:      c.VariationID,
-						VisitorID:        v.ID,
-						AnonymousID:      v.AnonymousID,
+						VisitorID:        state.id,
+						AnonymousID:      state.anonymousID,
 					})
+					if err == nil {
+						v.setCampaignActivated(campaignID)
+					}
 					return err
 				}
 			}
@@ -494,8 +697,22 @@ func (v *Visitor) SendHit(hit model.HitInterface) (err error) {
 		}
 	}()
 
-	visitorLogger.Info(fmt.Sprintf("Sending hit for visitor with id : %s", v.ID))
-	err = v.trackingAPIClient.SendHit(v.ID, v.AnonymousID, hit)
+	if v.panicMode.isEnabled() {
+		visitorLogger.Info("Environment is in panic mode. Skipping hit")
+		return nil
+	}
+
+	state := v.getState()
+	if !state.hasConsented && !model.IsConsentHit(hit) {
+		visitorLogger.Info("Visitor has not consented to be tracked. Skipping hit")
+		return nil
+	}
+
+	visitorLogger.Info(fmt.Sprintf("Sending hit for visitor with id : %s", state.id))
+	if state.hasConsented {
+		v.sessionTracker.stamp(state.id, state.anonymousID, hit)
+	}
+	err = v.trackingAPIClient.SendHit(state.id, state.anonymousID, hit)
 
 	if err != nil {
 		err = fmt.Errorf("Error when registering hit: %s", err.Error())
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
+)
+
+// VISITOR_SNAPSHOT_VERSION is the version of the visitor snapshots produced by Visitor.Export
+const VISITOR_SNAPSHOT_VERSION = 1
+
+// VisitorSnapshot represents the exported state of a visitor
+type VisitorSnapshot struct {
+	Version            int                      `json:"version"`
+	EnvID              string                   `json:"envId"`
+	VisitorID          string                   `json:"visitorId"`
+	AnonymousID        *string                  `json:"anonymousId,omitempty"`
+	Context            model.Context            `json:"context"`
+	HasConsented       *bool                    `json:"hasConsented,omitempty"`
+	DecisionResponse   *model.APIClientResponse `json:"decisionResponse,omitempty"`
+	DecisionSource     model.DecisionSource     `json:"decisionSource,omitempty"`
+	FetchStatus        FetchFlagsStatus         `json:"fetchStatus"`
+	ActivatedCampaigns []string                 `json:"activatedCampaigns,omitempty"`
+	ExportedAt         time.Time                `json:"exportedAt"`
+}
+
+// setCampaignActivated records that the campaign has been activated for the visitor
+func (v *Visitor) setCampaignActivated(campaignID string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.activatedCampaigns[campaignID] {
+		return
+	}
+	activatedCampaigns := make(map[string]bool, len(v.activatedCampaigns)+1)
+	for id := range v.activatedCampaigns {
+		activatedCampaigns[id] = true
+	}
+	activatedCampaigns[campaignID] = true
+	v.activatedCampaigns = activatedCampaigns
+}
+
+// GetActivatedCampaigns returns the sorted IDs of the campaigns activated for the visitor ID, as exposure state exported with the visitor.
+// It does not prevent the campaigns from being activated again
+func (v *Visitor) GetActivatedCampaigns() []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	campaignIDs := []string{}
+	for id := range v.activatedCampaigns {
+		campaignIDs = append(campaignIDs, id)
+	}
+	sort.Strings(campaignIDs)
+	return campaignIDs
+}
+
+// Export returns a versioned JSON snapshot of the visitor, that can be restored with Client.ImportVisitor
+func (v *Visitor) Export() (snapshot []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = utils.HandleRecovered(r, visitorLogger)
+		}
+	}()
+
+	activatedCampaigns := v.GetActivatedCampaigns()
+
+	v.mu.Lock()
+	v.refreshFetchStatus()
+	hasConsented := v.hasConsented
+	visitorSnapshot := VisitorSnapshot{
+		Version:            VISITOR_SNAPSHOT_VERSION,
+		EnvID:              v.envID,
+		VisitorID:          v.ID,
+		AnonymousID:        v.AnonymousID,
+		Context:            v.Context,
+		HasConsented:       &hasConsented,
+		DecisionResponse:   v.decisionResponse,
+		FetchStatus:        v.fetchStatus,
+		ActivatedCampaigns: activatedCampaigns,
+		ExportedAt:         time.Now(),
+	}
+	if v.decisionResponse != nil {
+		visitorSnapshot.DecisionSource = v.decisionResponse.Source
+	}
+	v.mu.Unlock()
+
+	return json.Marshal(visitorSnapshot)
+}
+
+// ImportVisitor restores a visitor from a snapshot produced by Visitor.Export, without calling the decision engine
+func (c *Client) ImportVisitor(snapshot []byte) (visitor *Visitor, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = utils.HandleRecovered(r, clientLogger)
+		}
+	}()
+
+	visitorSnapshot := VisitorSnapshot{}
+	err = json.Unmarshal(snapshot, &visitorSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid visitor snapshot : %v", err)
+	}
+
+	if visitorSnapshot.Version != VISITOR_SNAPSHOT_VERSION {
+		return nil, fmt.Errorf("Visitor snapshot version %d not handled. Expected version %d", visitorSnapshot.Version, VISITOR_SNAPSHOT_VERSION)
+	}
+
+	if visitorSnapshot.EnvID != c.envID {
+		return nil, fmt.Errorf("Visitor snapshot environment ID %s does not match the client environment ID %s", visitorSnapshot.EnvID, c.envID)
+	}
+
+	if visitorSnapshot.VisitorID == "" {
+		return nil, errors.New("Visitor snapshot ID should not be empty")
+	}
+
+	// The reserved keys of the snapshot context are replaced by the ones of the client
+	importedContext := model.Context{}
+	for k, val := range visitorSnapshot.Context {
+		if !model.IsReservedContextKey(k) {
+			importedContext[k] = val
+		}
+	}
+
+	errs := importedContext.Validate()
+	if len(errs) > 0 {
+		errorStrings := []string{}
+		for _, e := range errs {
+			errorStrings = append(errorStrings, e.Error())
+		}
+		return nil, fmt.Errorf("Invalid context : %s", strings.Join(errorStrings, ", "))
+	}
+
+	clientLogger.Info(fmt.Sprintf("Importing visitor with id : %s", visitorSnapshot.VisitorID))
+
+	visitor = &Visitor{
+		ID:                visitorSnapshot.VisitorID,
+		AnonymousID:       visitorSnapshot.AnonymousID,
+		Context:           c.buildVisitorContext(importedContext),
+		baseContext:       c.baseContext,
+		envID:             c.envID,
+		hasConsented:      visitorSnapshot.HasConsented == nil || *visitorSnapshot.HasConsented,
+		decisionClient:    c.decisionClient,
+		decisionMode:      c.decisionMode,
+		trackingAPIClient: c.trackingAPIClient,
+		cacheManager:      c.cacheManager,
+		sessionTracker:    c.sessionTracker,
+		panicMode:         c.panicMode,
+		maxGetterWait:     c.maxGetterWait,
+		fetchStatus: FetchFlagsStatus{
+			Status: FETCH_STATUS_REQUIRED,
+			Reason: FETCH_REASON_VISITOR_CREATED,
+		},
+	}
+
+	if resp := visitorSnapshot.DecisionResponse; resp != nil {
+		resp.Source = visitorSnapshot.DecisionSource
+		visitor.decisionResponse = resp
+		visitor.flagInfos = getFlagInfos(resp)
+		visitor.fetchStatus = visitorSnapshot.FetchStatus
+		// The decision engine configuration of the exporting process is unknown, so the flags are considered up to date
+		if targetingInfo, ok := c.decisionClient.(decision.TargetingInfoInterface); ok {
+			visitor.configVersion = targetingInfo.GetConfigVersion()
+		}
+	}
+
+	if len(visitorSnapshot.ActivatedCampaigns) > 0 {
+		visitor.activatedCampaigns = map[string]bool{}
+		for _, id := range visitorSnapshot.ActivatedCampaigns {
+			visitor.activatedCampaigns[id] = true
+		}
+	}
+
+	return visitor, nil
+}
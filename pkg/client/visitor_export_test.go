@@ -0,0 +1,76 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decision"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportImportVisitor(t *testing.T) {
+	client := createClient()
+	client.decisionClient = createMockClient()
+	trackingAPIClient := &countingTrackingAPIClient{}
+	client.trackingAPIClient = trackingAPIClient
+
+	visitor, _ := client.NewVisitor("anonymous", model.Context{"key": "value", "number": 3})
+	_ = visitor.Authenticate("logged", nil, true)
+	assert.Nil(t, visitor.ActivateModification("test_string"))
+
+	snapshot, err := visitor.Export()
+	assert.Nil(t, err)
+
+	// The imported visitor is restored without calling the decision engine
+	client.decisionClient = decision.NewAPIClientMock(testEnvID, nil, 500)
+	imported, err := client.ImportVisitor(snapshot)
+	assert.Nil(t, err)
+	assert.Equal(t, "logged", imported.GetID())
+	assert.Equal(t, "anonymous", *imported.GetAnonymousID())
+	assert.Equal(t, client.buildVisitorContext(model.Context{"key": "value", "number": 3.}), imported.GetContext())
+	assert.Equal(t, []string{caID}, imported.GetActivatedCampaigns())
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_FETCHED, Reason: FETCH_REASON_NONE}, imported.GetFetchStatus())
+
+	value, err := imported.GetModificationString("test_string", "default", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "string", value)
+	assert.Equal(t, int64(2), trackingAPIClient.activations)
+
+	// The activated campaigns are recorded per visitor ID
+	assert.Nil(t, imported.Unauthenticate(nil, false))
+	assert.Empty(t, imported.GetActivatedCampaigns())
+	assert.Nil(t, imported.ActivateModification("test_string"))
+	assert.Equal(t, []string{caID}, imported.GetActivatedCampaigns())
+	assert.Equal(t, int64(3), trackingAPIClient.activations)
+
+	info, _ := imported.GetModificationInfo("test_object")
+	assert.Equal(t, vgID, info.VariationGroupID)
+}
+
+func TestImportVisitorValidation(t *testing.T) {
+	client := createClient()
+
+	_, err := client.ImportVisitor([]byte("not json"))
+	assert.NotNil(t, err)
+
+	snapshot, _ := json.Marshal(VisitorSnapshot{Version: 2, EnvID: testEnvID, VisitorID: testVID})
+	_, err = client.ImportVisitor(snapshot)
+	assert.NotNil(t, err)
+
+	snapshot, _ = json.Marshal(VisitorSnapshot{Version: VISITOR_SNAPSHOT_VERSION, EnvID: "other_env_id", VisitorID: testVID})
+	_, err = client.ImportVisitor(snapshot)
+	assert.NotNil(t, err)
+
+	snapshot, _ = json.Marshal(VisitorSnapshot{Version: VISITOR_SNAPSHOT_VERSION, EnvID: testEnvID})
+	_, err = client.ImportVisitor(snapshot)
+	assert.NotNil(t, err)
+
+	// A visitor exported before synchronization requires a fetch
+	visitor, _ := client.NewVisitor(testVID, nil)
+	snapshot, _ = visitor.Export()
+	imported, err := client.ImportVisitor(snapshot)
+	assert.Nil(t, err)
+	assert.Equal(t, FetchFlagsStatus{Status: FETCH_STATUS_REQUIRED, Reason: FETCH_REASON_VISITOR_CREATED}, imported.GetFetchStatus())
+	assert.Nil(t, imported.GetAllModifications())
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// generateAnonymousID returns a random (version 4) UUID to identify a visitor
+func generateAnonymousID() string {
+	uuid := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, uuid); err != nil {
+		panic(fmt.Sprintf("Error when generating visitor ID : %v", err))
+	}
+
+	// Set the version (4) and the variant (RFC 4122) bits
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
+// generateVisitorID returns a new visitor ID from the client generator, or a random UUID by default
+func (c *Client) generateVisitorID() string {
+	if c.visitorIDGenerator != nil {
+		if id := c.visitorIDGenerator(); id != "" {
+			return id
+		}
+		clientLogger.Warn("Visitor ID generator returned an empty ID. Using a random UUID instead")
+	}
+	return generateAnonymousID()
+}
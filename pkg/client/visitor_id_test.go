@@ -0,0 +1,68 @@
+package client
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAnonymousID(t *testing.T) {
+	uuidRegexp := regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+	assert.Regexp(t, uuidRegexp, generateAnonymousID())
+}
+
+func TestVisitorIDUniqueness(t *testing.T) {
+	client := createClient()
+
+	goroutines := 50
+	visitorsPerGoroutine := 200
+	ids := make(chan string, goroutines*visitorsPerGoroutine*2)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < visitorsPerGoroutine; j++ {
+				visitor, err := client.NewVisitor("", model.Context{}, WithAuthenticated(true))
+				assert.Nil(t, err)
+				ids <- visitor.ID
+				ids <- *visitor.AnonymousID
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := map[string]bool{}
+	for id := range ids {
+		assert.False(t, seen[id], "Duplicated visitor ID %s", id)
+		seen[id] = true
+	}
+	assert.Equal(t, goroutines*visitorsPerGoroutine*2, len(seen))
+}
+
+func TestWithVisitorIDGenerator(t *testing.T) {
+	options := &Options{
+		EnvID:  testEnvID,
+		APIKey: testAPIKey,
+	}
+	generatedID := "custom_id"
+	options.BuildOptions(WithVisitorIDGenerator(func() string { return generatedID }))
+	client, _ := Create(options)
+
+	visitor, _ := client.NewVisitor("", model.Context{}, WithAuthenticated(true))
+	assert.Equal(t, "custom_id", visitor.ID)
+	assert.Equal(t, "custom_id", *visitor.AnonymousID)
+
+	visitor, _ = client.NewVisitor(vID, model.Context{})
+	assert.Equal(t, vID, visitor.ID)
+
+	// An empty generated ID falls back to the default generator
+	generatedID = ""
+	visitor, _ = client.NewVisitor("", model.Context{})
+	assert.NotEqual(t, "", visitor.ID)
+}
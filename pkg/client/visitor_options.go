@@ -3,6 +3,8 @@ package client
 // VisitorOptions represents the visitor options of the Flagship SDK
 type VisitorOptions struct {
 	IsAuthenticated bool
+	AnonymousID     string
+	HasConsented    bool
 }
 
 // VisitorOptionBuilder is a func type to set options to the VisitorOptions.
@@ -15,6 +17,21 @@ func WithAuthenticated(isAuthenticated bool) VisitorOptionBuilder {
 	}
 }
 
+// WithAnonymousID sets the anonymous ID of a visitor created authenticated, so that it is kept across the visitor instances.
+// A new anonymous ID is generated by default
+func WithAnonymousID(anonymousID string) VisitorOptionBuilder {
+	return func(f *VisitorOptions) {
+		f.AnonymousID = anonymousID
+	}
+}
+
+// WithConsent sets the consent of the visitor to be tracked. Visitors have consented by default
+func WithConsent(hasConsented bool) VisitorOptionBuilder {
+	return func(f *VisitorOptions) {
+		f.HasConsented = hasConsented
+	}
+}
+
 // BuildVisitorOptions fill out the FlagshipOption struct from option builders
 func (f *VisitorOptions) BuildVisitorOptions(visitorOptions ...VisitorOptionBuilder) {
 	// extract options
@@ -2,6 +2,8 @@ package client
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/bucketing"
@@ -137,7 +139,7 @@ func TestAuthenticate(t *testing.T) {
 	}
 	visitor.Authenticate("newerID", newContext, false)
 	assert.Equal(t, "newerID", visitor.ID)
-	assert.Equal(t, newContext, visitor.Context)
+	assert.Equal(t, mergeContexts(visitor.baseContext, newContext), visitor.Context)
 	assert.Equal(t, "firstID", *visitor.AnonymousID)
 
 	visitor.decisionMode = API
@@ -147,7 +149,7 @@ func TestAuthenticate(t *testing.T) {
 	err = visitor.Unauthenticate(newContext, false)
 	assert.Nil(t, err)
 	assert.Equal(t, "firstID", visitor.ID)
-	assert.Equal(t, newContext, visitor.Context)
+	assert.Equal(t, mergeContexts(visitor.baseContext, newContext), visitor.Context)
 	assert.Nil(t, visitor.AnonymousID)
 
 	visitor = createVisitor("firstID", context, WithAuthenticated(false))
@@ -155,6 +157,13 @@ func TestAuthenticate(t *testing.T) {
 
 	visitor = createVisitor("firstID", context, WithAuthenticated(true))
 	assert.NotNil(t, visitor.AnonymousID)
+
+	visitor = createVisitor("firstID", context, WithAuthenticated(true), WithAnonymousID("anonymousID"))
+	assert.Equal(t, "anonymousID", *visitor.AnonymousID)
+
+	// The anonymous ID is only set to the visitors created authenticated
+	visitor = createVisitor("firstID", context, WithAnonymousID("anonymousID"))
+	assert.Nil(t, visitor.AnonymousID)
 }
 
 func TestSynchronizeModifications(t *testing.T) {
@@ -477,3 +486,122 @@ func TestSendHitVisitor(t *testing.T) {
 		t.Errorf("Did not expect error as hit is correct. Got %v", err)
 	}
 }
+
+func TestHybridDecisionSource(t *testing.T) {
+	client := createClient()
+	client.decisionMode = Hybrid
+	client.decisionClient = decision.NewHybridClient(
+		decision.NewAPIClientMock(testEnvID, nil, 500),
+		bucketing.GetBucketingEngineMock(testEnvID, nil),
+		decision.HybridOptions{})
+	client.trackingAPIClient = &FakeTrackingAPIClient{}
+
+	visitor, _ := client.NewVisitor("test", map[string]interface{}{
+		"test": true,
+	})
+	assert.Equal(t, model.DecisionSource(""), visitor.GetDecisionSource())
+
+	err := visitor.SynchronizeModifications()
+	assert.Nil(t, err)
+	assert.Equal(t, model.SOURCE_BUCKETING, visitor.GetDecisionSource())
+
+	_, ok := visitor.GetAllModifications()["test"]
+	assert.True(t, ok)
+}
+
+type blockingDecisionClient struct {
+	decision.ClientInterface
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingDecisionClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	c.started <- struct{}{}
+	<-c.release
+	return c.ClientInterface.GetModifications(visitorID, anonymousID, context)
+}
+
+func TestVisitorConcurrency(t *testing.T) {
+	visitor := createVisitor("test", model.Context{"key": "value"})
+	assert.Nil(t, visitor.SynchronizeModifications())
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = visitor.UpdateContextKey(fmt.Sprintf("key_%d", i%5), i)
+			_ = visitor.SynchronizeModifications()
+			_, _ = visitor.GetModificationString("test_string", "default", true)
+			_, _ = visitor.GetModificationInfo("test_object")
+			_ = visitor.GetAllModifications()
+			_ = visitor.GetContext()
+			_ = visitor.GetFetchStatus()
+			_ = visitor.SendHit(&model.EventHit{Action: "action"})
+			if i%2 == 0 {
+				_ = visitor.Authenticate("logged", model.Context{"key": i}, true)
+			} else {
+				_ = visitor.Unauthenticate(nil, false)
+			}
+			_ = visitor.SynchronizeIfNeeded()
+		}(i)
+	}
+	wg.Wait()
+
+	value, err := visitor.GetModificationString("test_string", "default", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "string", value)
+}
+
+func TestVisitorSnapshots(t *testing.T) {
+	context := model.Context{"key": "value"}
+	visitor := createVisitor("test", context)
+
+	// The visitor context is not shared with the caller
+	context["key"] = "changed"
+	assert.Equal(t, "value", visitor.GetContext()["key"])
+
+	visitorContext := visitor.GetContext()
+	visitorContext["key"] = "changed"
+	assert.Equal(t, "value", visitor.GetContext()["key"])
+
+	_ = visitor.SynchronizeModifications()
+	objectVal, _ := visitor.GetModificationObject("test_object", nil, false)
+	objectVal["test_key"] = false
+	objectVal, _ = visitor.GetModificationObject("test_object", nil, false)
+	assert.Equal(t, true, objectVal["test_key"])
+
+	_ = visitor.Authenticate("logged", nil, false)
+	assert.Equal(t, "logged", visitor.GetID())
+	assert.Equal(t, "test", *visitor.GetAnonymousID())
+}
+
+func TestVisitorChangedDuringSync(t *testing.T) {
+	visitor := createVisitor("test", nil)
+	blockingClient := &blockingDecisionClient{
+		ClientInterface: visitor.decisionClient,
+		started:         make(chan struct{}),
+		release:         make(chan struct{}),
+	}
+	visitor.decisionClient = blockingClient
+
+	done := make(chan error)
+	go func() {
+		done <- visitor.SynchronizeModifications()
+	}()
+
+	<-blockingClient.started
+	_ = visitor.UpdateContextKey("key", "value")
+	close(blockingClient.release)
+	assert.Nil(t, <-done)
+
+	// The flags are set but still outdated as the context changed during the synchronization
+	assert.NotNil(t, visitor.GetAllModifications()["test_string"])
+	assert.Equal(t, FETCH_STATUS_REQUIRED, visitor.GetFetchStatus().Status)
+
+	go func() {
+		<-blockingClient.started
+	}()
+	assert.Nil(t, visitor.SynchronizeIfNeeded())
+	assert.Equal(t, FETCH_STATUS_FETCHED, visitor.GetFetchStatus().Status)
+}
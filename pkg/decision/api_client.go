@@ -31,3 +31,9 @@ func (r *APIClient) GetModifications(visitorID string, anonymousID *string, cont
 	apiLogger.Info("Getting modifications from API")
 	return r.decisionAPIClient.GetModifications(visitorID, anonymousID, context)
 }
+
+// GetModificationsWithConsent gets modifications from Decision API, sending the visitor consent
+func (r *APIClient) GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	apiLogger.Info("Getting modifications from API")
+	return r.decisionAPIClient.GetModificationsWithConsent(visitorID, anonymousID, context, hasConsented)
+}
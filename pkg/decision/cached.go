@@ -0,0 +1,282 @@
+package decision
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+const defaultCacheTTL = 1 * time.Minute
+const defaultCacheMaxSize = 10000
+
+var cacheLogger = logging.CreateLogger("Decision Cache")
+
+// CacheOptions represents the options of the decision response cache
+type CacheOptions struct {
+	// TTL is the duration during which a cached response is served without calling the decision client. Defaults to 1 minute
+	TTL time.Duration
+	// StaleTTL is the additional duration during which an expired response is served while being refreshed in the background.
+	// If 0, expired responses are never served
+	StaleTTL time.Duration
+	// MaxSize is the maximum number of cached responses. The least recently used responses are evicted first. Defaults to 10000
+	MaxSize int
+}
+
+// CacheStats represents the counters of the decision response cache
+type CacheStats struct {
+	Hits      int64
+	StaleHits int64
+	Misses    int64
+	Evictions int64
+}
+
+type cacheEntry struct {
+	key         string
+	visitorID   string
+	anonymousID string
+	response    *model.APIClientResponse
+	createdAt   time.Time
+	refreshing  bool
+}
+
+// CachedClient caches in memory the responses of a decision client by visitor ID, anonymous ID and context.
+// Cached responses are shared between callers and must not be modified. The panic mode responses are not cached
+type CachedClient struct {
+	primary   ClientInterface
+	ttl       time.Duration
+	staleTTL  time.Duration
+	maxSize   int
+	entries   map[string]*list.Element
+	lru       *list.List
+	mux       sync.Mutex
+	hits      int64
+	staleHits int64
+	misses    int64
+	evictions int64
+}
+
+// NewCachedClient creates a decision response cache in front of the decision client
+func NewCachedClient(primary ClientInterface, options CacheOptions) *CachedClient {
+	res := &CachedClient{
+		primary:  primary,
+		ttl:      options.TTL,
+		staleTTL: options.StaleTTL,
+		maxSize:  options.MaxSize,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+	}
+
+	if res.ttl <= 0 {
+		res.ttl = defaultCacheTTL
+	}
+
+	if res.maxSize <= 0 {
+		res.maxSize = defaultCacheMaxSize
+	}
+
+	return res
+}
+
+// hashDecisionRequest computes a key from the visitor IDs and the context of a decision request
+func hashDecisionRequest(visitorID string, anonymousID *string, context model.Context) (string, error) {
+	// JSON encoding sorts the context keys, so that the hash is the same for equal contexts
+	contextJSON, err := json.Marshal(context)
+	if err != nil {
+		return "", err
+	}
+
+	anonymousIDString := ""
+	if anonymousID != nil {
+		anonymousIDString = *anonymousID
+	}
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%d:%s|%d:%s|", len(visitorID), visitorID, len(anonymousIDString), anonymousIDString)
+	_, _ = h.Write(contextJSON)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// GetModifications gets modifications from the cache, or from the decision client if they are not cached
+func (c *CachedClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	return c.getModifications(visitorID, anonymousID, context, nil)
+}
+
+// GetModificationsWithConsent gets modifications like GetModifications, passing the visitor consent to the decision client.
+// The responses are cached by consent
+func (c *CachedClient) GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	return c.getModifications(visitorID, anonymousID, context, &hasConsented)
+}
+
+func (c *CachedClient) getModifications(visitorID string, anonymousID *string, context model.Context, hasConsented *bool) (*model.APIClientResponse, error) {
+	key, err := getRequestKey(c.primary, visitorID, anonymousID, context, hasConsented)
+	if err != nil {
+		cacheLogger.Warnf("Could not compute decision cache key: %v", err)
+		return getModifications(c.primary, visitorID, anonymousID, context, hasConsented)
+	}
+
+	c.mux.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		age := time.Since(entry.createdAt)
+		if age <= c.ttl {
+			c.lru.MoveToFront(element)
+			c.mux.Unlock()
+			atomic.AddInt64(&c.hits, 1)
+			return entry.response, nil
+		}
+
+		if age <= c.ttl+c.staleTTL {
+			c.lru.MoveToFront(element)
+			if !entry.refreshing {
+				entry.refreshing = true
+				go c.refresh(key, visitorID, copyAnonymousID(anonymousID), copyContext(context), hasConsented)
+			}
+			c.mux.Unlock()
+			atomic.AddInt64(&c.staleHits, 1)
+			return entry.response, nil
+		}
+	}
+	c.mux.Unlock()
+
+	atomic.AddInt64(&c.misses, 1)
+	resp, err := getModifications(c.primary, visitorID, anonymousID, context, hasConsented)
+	if err != nil {
+		return nil, err
+	}
+
+	// The panic mode responses are not cached, so that the panic mode is left as soon as the decision client reports it
+	if !resp.Panic {
+		c.set(key, visitorID, anonymousID, resp, false)
+	}
+	return resp, nil
+}
+
+// refresh gets the modifications from the decision client in the background and updates the cache
+func (c *CachedClient) refresh(key string, visitorID string, anonymousID *string, context model.Context, hasConsented *bool) {
+	resp, err := getModifications(c.primary, visitorID, anonymousID, context, hasConsented)
+	if err != nil {
+		cacheLogger.Warnf("Background decision refresh failed for visitor %s: %v", visitorID, err)
+		c.mux.Lock()
+		if element, ok := c.entries[key]; ok {
+			element.Value.(*cacheEntry).refreshing = false
+		}
+		c.mux.Unlock()
+		return
+	}
+	if resp.Panic {
+		c.remove(key)
+		return
+	}
+	// The entry is not added back if it has been removed during the refresh
+	c.set(key, visitorID, anonymousID, resp, true)
+}
+
+// remove removes the cached response of the decision request
+func (c *CachedClient) remove(key string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+// set caches the response of the decision request, or only updates it if onlyIfPresent is true
+func (c *CachedClient) set(key string, visitorID string, anonymousID *string, resp *model.APIClientResponse, onlyIfPresent bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	entry := &cacheEntry{key: key, visitorID: visitorID, response: resp, createdAt: time.Now()}
+	if anonymousID != nil {
+		entry.anonymousID = *anonymousID
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	if onlyIfPresent {
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		atomic.AddInt64(&c.evictions, 1)
+	}
+}
+
+// ForgetVisitor removes the cached responses of the visitor, whether it is their visitor ID or anonymous ID.
+// It returns the number of removed responses and the other IDs of the visitor found in these responses
+func (c *CachedClient) ForgetVisitor(visitorID string) (removed int, linkedIDs []string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	linked := map[string]bool{}
+	for key, element := range c.entries {
+		entry := element.Value.(*cacheEntry)
+		if entry.visitorID != visitorID && entry.anonymousID != visitorID {
+			continue
+		}
+		for _, id := range []string{entry.visitorID, entry.anonymousID} {
+			if id != "" && id != visitorID {
+				linked[id] = true
+			}
+		}
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		removed++
+	}
+
+	for id := range linked {
+		linkedIDs = append(linkedIDs, id)
+	}
+	sort.Strings(linkedIDs)
+	return removed, linkedIDs
+}
+
+// Len returns the number of cached responses
+func (c *CachedClient) Len() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.lru.Len()
+}
+
+// GetStats returns the counters of the decision response cache
+func (c *CachedClient) GetStats() CacheStats {
+	return CacheStats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		StaleHits: atomic.LoadInt64(&c.staleHits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Evictions: atomic.LoadInt64(&c.evictions),
+	}
+}
+
+// GetPrimaryClient returns the cached decision client
+func (c *CachedClient) GetPrimaryClient() ClientInterface {
+	return c.primary
+}
+
+// GetConfigVersion returns the configuration version of the cached client if it knows the targeting
+func (c *CachedClient) GetConfigVersion() int64 {
+	return getConfigVersion(c.primary)
+}
+
+// IsTargetingContextKey checks the context key against the cached client targeting. Every key is considered targeted if the cached client does not know the targeting
+func (c *CachedClient) IsTargetingContextKey(key string) bool {
+	return isTargetingContextKey(c.primary, key)
+}
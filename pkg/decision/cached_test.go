@@ -0,0 +1,191 @@
+package decision
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingClient struct {
+	calls   int64
+	version int64
+	panic   int32
+	fail    bool
+}
+
+func (c *countingClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	calls := atomic.AddInt64(&c.calls, 1)
+	if c.fail {
+		return nil, errors.New("decision error")
+	}
+	if atomic.LoadInt32(&c.panic) == 1 {
+		return &model.APIClientResponse{VisitorID: visitorID, Panic: true}, nil
+	}
+	return &model.APIClientResponse{VisitorID: visitorID, Campaigns: []model.Campaign{{ID: string(rune('a' + calls))}}}, nil
+}
+
+type versionedCountingClient struct {
+	countingClient
+}
+
+func (c *versionedCountingClient) GetConfigVersion() int64 {
+	return atomic.LoadInt64(&c.version)
+}
+
+func (c *versionedCountingClient) IsTargetingContextKey(key string) bool {
+	return key == "targeted"
+}
+
+func TestHashDecisionRequest(t *testing.T) {
+	anonymousID := "anon"
+	key1, err := hashDecisionRequest("vid", nil, model.Context{"a": 1.0, "b": "value"})
+	assert.Nil(t, err)
+	key2, _ := hashDecisionRequest("vid", nil, model.Context{"b": "value", "a": 1.0})
+	assert.Equal(t, key1, key2)
+
+	key3, _ := hashDecisionRequest("vid", &anonymousID, model.Context{"a": 1.0, "b": "value"})
+	assert.NotEqual(t, key1, key3)
+
+	key4, _ := hashDecisionRequest("vid", nil, model.Context{"a": 2.0, "b": "value"})
+	assert.NotEqual(t, key1, key4)
+}
+
+func TestCachedClient(t *testing.T) {
+	primary := &countingClient{}
+	client := NewCachedClient(primary, CacheOptions{})
+	assert.Equal(t, defaultCacheTTL, client.ttl)
+	assert.Equal(t, defaultCacheMaxSize, client.maxSize)
+	assert.Equal(t, primary, client.GetPrimaryClient())
+
+	resp1, err := client.GetModifications("vid", nil, model.Context{"key": "value"})
+	assert.Nil(t, err)
+	resp2, _ := client.GetModifications("vid", nil, model.Context{"key": "value"})
+	assert.Equal(t, resp1, resp2)
+	assert.Equal(t, int64(1), primary.calls)
+
+	_, _ = client.GetModifications("vid", nil, model.Context{"key": "other"})
+	assert.Equal(t, int64(2), primary.calls)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2}, client.GetStats())
+
+	// Errors are not cached
+	failing := &countingClient{fail: true}
+	client = NewCachedClient(failing, CacheOptions{})
+	_, err = client.GetModifications("vid", nil, model.Context{})
+	assert.NotNil(t, err)
+	_, _ = client.GetModifications("vid", nil, model.Context{})
+	assert.Equal(t, int64(2), failing.calls)
+	assert.Equal(t, 0, client.Len())
+}
+
+func TestCachedClientLRU(t *testing.T) {
+	primary := &countingClient{}
+	client := NewCachedClient(primary, CacheOptions{MaxSize: 2})
+
+	_, _ = client.GetModifications("vid1", nil, model.Context{})
+	_, _ = client.GetModifications("vid2", nil, model.Context{})
+	_, _ = client.GetModifications("vid1", nil, model.Context{})
+	_, _ = client.GetModifications("vid3", nil, model.Context{})
+	assert.Equal(t, 2, client.Len())
+	assert.Equal(t, int64(1), client.GetStats().Evictions)
+
+	// vid2 was the least recently used
+	_, _ = client.GetModifications("vid1", nil, model.Context{})
+	assert.Equal(t, int64(3), primary.calls)
+	_, _ = client.GetModifications("vid2", nil, model.Context{})
+	assert.Equal(t, int64(4), primary.calls)
+}
+
+func TestCachedClientStaleWhileRevalidate(t *testing.T) {
+	primary := &countingClient{}
+	client := NewCachedClient(primary, CacheOptions{TTL: 50 * time.Millisecond, StaleTTL: 200 * time.Millisecond})
+
+	resp1, _ := client.GetModifications("vid", nil, model.Context{})
+	time.Sleep(60 * time.Millisecond)
+
+	// Stale response is served and refreshed in the background
+	resp2, _ := client.GetModifications("vid", nil, model.Context{})
+	assert.Equal(t, resp1, resp2)
+	assert.Equal(t, int64(1), client.GetStats().StaleHits)
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&primary.calls) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		resp3, _ := client.GetModifications("vid", nil, model.Context{})
+		return resp3 != resp1
+	}, time.Second, 5*time.Millisecond)
+
+	// Expired response after stale TTL is not served
+	time.Sleep(300 * time.Millisecond)
+	_, _ = client.GetModifications("vid", nil, model.Context{})
+	assert.Equal(t, int64(2), client.GetStats().Misses)
+}
+
+func TestCachedClientPanic(t *testing.T) {
+	primary := &countingClient{panic: 1}
+	client := NewCachedClient(primary, CacheOptions{TTL: 50 * time.Millisecond, StaleTTL: time.Second})
+
+	// Panic mode responses are not cached
+	resp, _ := client.GetModifications("vid", nil, model.Context{})
+	assert.True(t, resp.Panic)
+	assert.Equal(t, 0, client.Len())
+
+	atomic.StoreInt32(&primary.panic, 0)
+	resp, _ = client.GetModifications("vid", nil, model.Context{})
+	assert.False(t, resp.Panic)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&primary.calls))
+
+	// A panic mode response refreshing a stale response removes it
+	atomic.StoreInt32(&primary.panic, 1)
+	time.Sleep(60 * time.Millisecond)
+	_, _ = client.GetModifications("vid", nil, model.Context{})
+	assert.Eventually(t, func() bool { return client.Len() == 0 }, time.Second, 5*time.Millisecond)
+
+	resp, _ = client.GetModifications("vid", nil, model.Context{})
+	assert.True(t, resp.Panic)
+}
+
+func TestCachedClientConfigVersion(t *testing.T) {
+	primary := &versionedCountingClient{}
+	client := NewCachedClient(primary, CacheOptions{})
+	assert.True(t, client.IsTargetingContextKey("targeted"))
+	assert.False(t, client.IsTargetingContextKey("other"))
+
+	_, _ = client.GetModifications("vid", nil, model.Context{})
+	_, _ = client.GetModifications("vid", nil, model.Context{})
+	assert.Equal(t, int64(1), primary.calls)
+
+	atomic.AddInt64(&primary.version, 1)
+	assert.Equal(t, int64(1), client.GetConfigVersion())
+	_, _ = client.GetModifications("vid", nil, model.Context{})
+	assert.Equal(t, int64(2), primary.calls)
+}
+
+func TestCachedClientForgetVisitor(t *testing.T) {
+	primary := &countingClient{}
+	client := NewCachedClient(primary, CacheOptions{})
+	anonymousID := "anon"
+
+	_, _ = client.GetModifications("vid", nil, model.Context{"a": 1.0})
+	_, _ = client.GetModifications("vid", nil, model.Context{"a": 2.0})
+	_, _ = client.GetModifications("logged", &anonymousID, nil)
+	_, _ = client.GetModifications("other", nil, nil)
+	assert.Equal(t, 4, client.Len())
+
+	removed, linkedIDs := client.ForgetVisitor("vid")
+	assert.Equal(t, 2, removed)
+	assert.Nil(t, linkedIDs)
+
+	removed, linkedIDs = client.ForgetVisitor("anon")
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []string{"logged"}, linkedIDs)
+	assert.Equal(t, 1, client.Len())
+
+	// Forgotten responses are computed again
+	_, _ = client.GetModifications("vid", nil, model.Context{"a": 1.0})
+	assert.Equal(t, int64(5), atomic.LoadInt64(&primary.calls))
+}
@@ -0,0 +1,113 @@
+package decision
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
+)
+
+var coalescingLogger = logging.CreateLogger("Coalescing Client")
+
+// CoalescingStats represents the counters of the request coalescing
+type CoalescingStats struct {
+	Calls  int64
+	Shared int64
+}
+
+type inflightCall struct {
+	wg       sync.WaitGroup
+	response *model.APIClientResponse
+	err      error
+}
+
+// CoalescingClient shares a single decision client call between concurrent identical requests of the same visitor and context.
+// Shared responses must not be modified
+type CoalescingClient struct {
+	primary ClientInterface
+	calls   map[string]*inflightCall
+	mux     sync.Mutex
+	total   int64
+	shared  int64
+}
+
+// NewCoalescingClient creates a request coalescing layer in front of the decision client
+func NewCoalescingClient(primary ClientInterface) *CoalescingClient {
+	return &CoalescingClient{
+		primary: primary,
+		calls:   map[string]*inflightCall{},
+	}
+}
+
+// GetModifications gets modifications from the decision client, or waits for the in-flight identical request if any.
+// A panic of the decision client is returned as an error to every waiting request
+func (c *CoalescingClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	return c.getModifications(visitorID, anonymousID, context, nil)
+}
+
+// GetModificationsWithConsent gets modifications like GetModifications, passing the visitor consent to the decision client.
+// Only the requests with the same consent are coalesced
+func (c *CoalescingClient) GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	return c.getModifications(visitorID, anonymousID, context, &hasConsented)
+}
+
+func (c *CoalescingClient) getModifications(visitorID string, anonymousID *string, context model.Context, hasConsented *bool) (response *model.APIClientResponse, err error) {
+	key, err := getRequestKey(c.primary, visitorID, anonymousID, context, hasConsented)
+	if err != nil {
+		return getModifications(c.primary, visitorID, anonymousID, context, hasConsented)
+	}
+
+	c.mux.Lock()
+	if call, ok := c.calls[key]; ok {
+		c.mux.Unlock()
+		atomic.AddInt64(&c.shared, 1)
+		call.wg.Wait()
+		return call.response, call.err
+	}
+
+	call := &inflightCall{}
+	call.wg.Add(1)
+	c.calls[key] = call
+	c.mux.Unlock()
+
+	atomic.AddInt64(&c.total, 1)
+	defer func() {
+		if r := recover(); r != nil {
+			call.response, call.err = nil, utils.HandleRecovered(r, coalescingLogger)
+			response, err = call.response, call.err
+		}
+
+		c.mux.Lock()
+		delete(c.calls, key)
+		c.mux.Unlock()
+		call.wg.Done()
+	}()
+
+	call.response, call.err = getModifications(c.primary, visitorID, anonymousID, context, hasConsented)
+	return call.response, call.err
+}
+
+// GetStats returns the counters of the request coalescing
+func (c *CoalescingClient) GetStats() CoalescingStats {
+	return CoalescingStats{
+		Calls:  atomic.LoadInt64(&c.total),
+		Shared: atomic.LoadInt64(&c.shared),
+	}
+}
+
+// GetPrimaryClient returns the coalesced decision client
+func (c *CoalescingClient) GetPrimaryClient() ClientInterface {
+	return c.primary
+}
+
+// GetConfigVersion returns the configuration version of the coalesced client if it knows the targeting
+func (c *CoalescingClient) GetConfigVersion() int64 {
+	return getConfigVersion(c.primary)
+}
+
+// IsTargetingContextKey checks the context key against the coalesced client targeting. Every key is considered targeted if the coalesced client does not know the targeting
+func (c *CoalescingClient) IsTargetingContextKey(key string) bool {
+	return isTargetingContextKey(c.primary, key)
+}
@@ -0,0 +1,118 @@
+package decision
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingClient struct {
+	calls   int64
+	release chan struct{}
+}
+
+func (c *blockingClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	atomic.AddInt64(&c.calls, 1)
+	<-c.release
+	return &model.APIClientResponse{VisitorID: visitorID}, nil
+}
+
+func TestCoalescingClient(t *testing.T) {
+	primary := &blockingClient{release: make(chan struct{})}
+	client := NewCoalescingClient(primary)
+	assert.Equal(t, primary, client.GetPrimaryClient())
+
+	concurrency := 20
+	responses := make([]*model.APIClientResponse, concurrency)
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], _ = client.GetModifications("vid", nil, model.Context{"key": "value"})
+		}(i)
+	}
+
+	// Wait for all the requests to be in flight before releasing the decision call
+	assert.Eventually(t, func() bool {
+		return client.GetStats().Shared == int64(concurrency-1)
+	}, time.Second, time.Millisecond)
+	close(primary.release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&primary.calls))
+	assert.Equal(t, CoalescingStats{Calls: 1, Shared: int64(concurrency - 1)}, client.GetStats())
+	for _, resp := range responses {
+		assert.Equal(t, responses[0], resp)
+	}
+
+	// Finished calls are not shared anymore
+	_, _ = client.GetModifications("vid", nil, model.Context{"key": "value"})
+	assert.Equal(t, int64(2), atomic.LoadInt64(&primary.calls))
+}
+
+func TestCoalescingClientDifferentRequests(t *testing.T) {
+	primary := &blockingClient{release: make(chan struct{})}
+	client := NewCoalescingClient(primary)
+
+	wg := sync.WaitGroup{}
+	for _, context := range []model.Context{{"key": "value"}, {"key": "other"}} {
+		wg.Add(1)
+		go func(context model.Context) {
+			defer wg.Done()
+			_, _ = client.GetModifications("vid", nil, context)
+		}(context)
+	}
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&primary.calls) == 2
+	}, time.Second, time.Millisecond)
+	close(primary.release)
+	wg.Wait()
+
+	assert.Equal(t, CoalescingStats{Calls: 2}, client.GetStats())
+}
+
+type panickingClient struct {
+	blockingClient
+}
+
+func (c *panickingClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	atomic.AddInt64(&c.calls, 1)
+	<-c.release
+	panic("decision failure")
+}
+
+func TestCoalescingClientPanic(t *testing.T) {
+	primary := &panickingClient{blockingClient{release: make(chan struct{})}}
+	client := NewCoalescingClient(primary)
+
+	concurrency := 5
+	errs := make([]error, concurrency)
+	responses := make([]*model.APIClientResponse, concurrency)
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = client.GetModifications("vid", nil, model.Context{"key": "value"})
+		}(i)
+	}
+
+	assert.Eventually(t, func() bool {
+		return client.GetStats().Shared == int64(concurrency-1)
+	}, time.Second, time.Millisecond)
+	close(primary.release)
+	wg.Wait()
+
+	// The leader panic is returned as an error to every request
+	for i := 0; i < concurrency; i++ {
+		assert.NotNil(t, errs[i])
+		assert.Nil(t, responses[i])
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&primary.calls))
+}
@@ -0,0 +1,113 @@
+package decision
+
+import (
+	"sync"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+const defaultFailureThreshold = 5
+const defaultOpenDuration = 30 * time.Second
+
+var hybridLogger = logging.CreateLogger("Hybrid Client")
+
+// HybridOptions represents the options of the circuit breaker of the hybrid client
+type HybridOptions struct {
+	// FailureThreshold is the number of consecutive primary failures that opens the circuit. Defaults to 5
+	FailureThreshold int
+	// OpenDuration is the duration during which the primary client is skipped once the circuit is open. Defaults to 30s
+	OpenDuration time.Duration
+}
+
+// HybridClient gets decisions from a primary client and falls back to another client on failure or when the circuit is open
+type HybridClient struct {
+	primary          ClientInterface
+	fallback         ClientInterface
+	failureThreshold int
+	openDuration     time.Duration
+	failures         int
+	openUntil        time.Time
+	mux              sync.Mutex
+}
+
+// NewHybridClient creates a hybrid client with a primary and a fallback client
+func NewHybridClient(primary ClientInterface, fallback ClientInterface, options HybridOptions) *HybridClient {
+	res := &HybridClient{
+		primary:          primary,
+		fallback:         fallback,
+		failureThreshold: options.FailureThreshold,
+		openDuration:     options.OpenDuration,
+	}
+
+	if res.failureThreshold <= 0 {
+		res.failureThreshold = defaultFailureThreshold
+	}
+
+	if res.openDuration <= 0 {
+		res.openDuration = defaultOpenDuration
+	}
+
+	return res
+}
+
+// IsCircuitOpen returns true if the primary client is currently skipped
+func (c *HybridClient) IsCircuitOpen() bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return time.Now().Before(c.openUntil)
+}
+
+func (c *HybridClient) recordResult(succeeded bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if succeeded {
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.failures >= c.failureThreshold {
+		hybridLogger.Warnf("Primary decision client failed %d times. Opening circuit for %v", c.failures, c.openDuration)
+		c.openUntil = time.Now().Add(c.openDuration)
+		c.failures = 0
+	}
+}
+
+// GetModifications gets modifications from the primary client, or from the fallback client if it fails or if the circuit is open
+func (c *HybridClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	return c.getModifications(visitorID, anonymousID, context, nil)
+}
+
+// GetModificationsWithConsent gets modifications like GetModifications, passing the visitor consent to the primary and fallback clients
+func (c *HybridClient) GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	return c.getModifications(visitorID, anonymousID, context, &hasConsented)
+}
+
+func (c *HybridClient) getModifications(visitorID string, anonymousID *string, context model.Context, hasConsented *bool) (*model.APIClientResponse, error) {
+	if !c.IsCircuitOpen() {
+		resp, err := getModifications(c.primary, visitorID, anonymousID, context, hasConsented)
+		// A decision served from the visitor cache is returned as in Decision API mode, but the primary client failed
+		c.recordResult(err == nil && resp.Source != model.SOURCE_CACHE)
+		if err == nil {
+			return resp, nil
+		}
+		hybridLogger.Warnf("Primary decision client failed: %v. Falling back", err)
+	} else {
+		hybridLogger.Info("Circuit is open. Getting modifications from fallback client")
+	}
+
+	return getModifications(c.fallback, visitorID, anonymousID, context, hasConsented)
+}
+
+// GetPrimaryClient returns the client tried first
+func (c *HybridClient) GetPrimaryClient() ClientInterface {
+	return c.primary
+}
+
+// GetFallbackClient returns the client used when the primary client fails
+func (c *HybridClient) GetFallbackClient() ClientInterface {
+	return c.fallback
+}
@@ -0,0 +1,67 @@
+package decision
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHybridClient(t *testing.T) {
+	primaryResponse := &model.APIClientResponse{VisitorID: "test_vid", Source: model.SOURCE_DECISION_API}
+	fallbackResponse := &model.APIClientResponse{VisitorID: "test_vid", Source: model.SOURCE_BUCKETING}
+
+	client := NewHybridClient(NewAPIClientMock(testEnvID, primaryResponse, 200), NewAPIClientMock(testEnvID, fallbackResponse, 200), HybridOptions{})
+	assert.Equal(t, defaultFailureThreshold, client.failureThreshold)
+	assert.Equal(t, defaultOpenDuration, client.openDuration)
+
+	resp, err := client.GetModifications("test_vid", nil, model.Context{})
+	assert.Nil(t, err)
+	assert.Equal(t, model.SOURCE_DECISION_API, resp.Source)
+
+	client = NewHybridClient(NewAPIClientMock(testEnvID, nil, 500), NewAPIClientMock(testEnvID, fallbackResponse, 200), HybridOptions{
+		FailureThreshold: 2,
+		OpenDuration:     100 * time.Millisecond,
+	})
+
+	resp, err = client.GetModifications("test_vid", nil, model.Context{})
+	assert.Nil(t, err)
+	assert.Equal(t, model.SOURCE_BUCKETING, resp.Source)
+	assert.False(t, client.IsCircuitOpen())
+
+	_, _ = client.GetModifications("test_vid", nil, model.Context{})
+	assert.True(t, client.IsCircuitOpen())
+
+	// Primary client is skipped while the circuit is open
+	client.primary = NewAPIClientMock(testEnvID, primaryResponse, 200)
+	resp, _ = client.GetModifications("test_vid", nil, model.Context{})
+	assert.Equal(t, model.SOURCE_BUCKETING, resp.Source)
+
+	time.Sleep(150 * time.Millisecond)
+	assert.False(t, client.IsCircuitOpen())
+	resp, _ = client.GetModifications("test_vid", nil, model.Context{})
+	assert.Equal(t, model.SOURCE_DECISION_API, resp.Source)
+
+	// Fallback errors are returned
+	client = NewHybridClient(NewAPIClientMock(testEnvID, nil, 500), NewAPIClientMock(testEnvID, nil, 500), HybridOptions{})
+	_, err = client.GetModifications("test_vid", nil, model.Context{})
+	assert.NotNil(t, err)
+	assert.IsType(t, &APIClientMock{}, client.GetPrimaryClient())
+	assert.IsType(t, &APIClientMock{}, client.GetFallbackClient())
+}
+
+func TestHybridClientVisitorCache(t *testing.T) {
+	cachedResponse := &model.APIClientResponse{VisitorID: "test_vid", Source: model.SOURCE_CACHE}
+	fallbackResponse := &model.APIClientResponse{VisitorID: "test_vid", Source: model.SOURCE_BUCKETING}
+
+	// The decision served from the visitor cache is returned, but counts as a primary client failure
+	client := NewHybridClient(NewAPIClientMock(testEnvID, cachedResponse, 200), NewAPIClientMock(testEnvID, fallbackResponse, 200), HybridOptions{FailureThreshold: 2})
+	resp, err := client.GetModifications("test_vid", nil, model.Context{})
+	assert.Nil(t, err)
+	assert.Equal(t, model.SOURCE_CACHE, resp.Source)
+	assert.False(t, client.IsCircuitOpen())
+
+	_, _ = client.GetModifications("test_vid", nil, model.Context{})
+	assert.True(t, client.IsCircuitOpen())
+}
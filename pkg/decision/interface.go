@@ -6,3 +6,26 @@ import "github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
 type ClientInterface interface {
 	GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error)
 }
+
+// ConsentClientInterface is implemented by the modification engines that use the visitor consent
+type ConsentClientInterface interface {
+	GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error)
+}
+
+// TargetingInfoInterface is implemented by the modification engines that know the targeting of the environment configuration.
+// The configuration version is 0 until a configuration is loaded
+type TargetingInfoInterface interface {
+	GetConfigVersion() int64
+	IsTargetingContextKey(key string) bool
+}
+
+// ForgetterInterface is implemented by the modification engines that keep visitor data in memory
+type ForgetterInterface interface {
+	// ForgetVisitor removes the data of the visitor and returns the number of removed entries and the other IDs of the visitor found in them
+	ForgetVisitor(visitorID string) (removed int, linkedIDs []string)
+}
+
+// WrapperInterface is implemented by the modification engines that wrap another modification engine
+type WrapperInterface interface {
+	GetPrimaryClient() ClientInterface
+}
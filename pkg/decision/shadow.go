@@ -0,0 +1,264 @@
+package decision
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+var shadowLogger = logging.CreateLogger("Shadow Client")
+
+const defaultShadowMaxConcurrency = 10
+
+// ShadowOptions represents the options of the shadow comparison
+type ShadowOptions struct {
+	// SampleRate is the fraction of visitors, between 0 and 1, for which decisions are compared
+	SampleRate float64
+	// OnMismatch is called in the background when the decisions of a sampled visitor differ
+	OnMismatch func(mismatch ShadowMismatch)
+	// MaxConcurrency is the maximum number of comparisons running at the same time. Defaults to 10.
+	// The comparisons of the sampled visitors are dropped while the maximum is reached
+	MaxConcurrency int
+}
+
+// ShadowDifference represents a single difference between the primary and shadow decisions
+type ShadowDifference struct {
+	CampaignID string
+	// Field is one of campaign, variationGroupId, variationId or flag:<key>
+	Field   string
+	Primary interface{}
+	Shadow  interface{}
+}
+
+// ShadowMismatch represents the differences found for a visitor decision
+type ShadowMismatch struct {
+	VisitorID       string
+	Context         model.Context
+	Differences     []ShadowDifference
+	PrimaryResponse *model.APIClientResponse
+	ShadowResponse  *model.APIClientResponse
+}
+
+// ShadowStats represents the counters of the shadow comparison
+type ShadowStats struct {
+	Compared   int64
+	Mismatches int64
+	Errors     int64
+	Dropped    int64
+}
+
+// ShadowClient serves decisions from a primary client and compares them with a shadow client in the background
+type ShadowClient struct {
+	primary    ClientInterface
+	shadow     ClientInterface
+	sampleRate float64
+	onMismatch func(mismatch ShadowMismatch)
+	compared   int64
+	mismatches int64
+	errors     int64
+	dropped    int64
+	slots      chan struct{}
+	closed     bool
+	closeMux   sync.RWMutex
+	wg         sync.WaitGroup
+}
+
+// NewShadowClient creates a shadow client serving the primary client decisions
+func NewShadowClient(primary ClientInterface, shadow ClientInterface, options ShadowOptions) *ShadowClient {
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultShadowMaxConcurrency
+	}
+
+	return &ShadowClient{
+		primary:    primary,
+		shadow:     shadow,
+		sampleRate: options.SampleRate,
+		onMismatch: options.OnMismatch,
+		slots:      make(chan struct{}, maxConcurrency),
+	}
+}
+
+// isSampled deterministically checks if the visitor is part of the compared fraction of visitors
+func (c *ShadowClient) isSampled(visitorID string) bool {
+	if c.sampleRate <= 0 {
+		return false
+	}
+	if c.sampleRate >= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(visitorID))
+	return float64(h.Sum32()%10000) < c.sampleRate*10000
+}
+
+// GetModifications gets modifications from the primary client and compares them with the shadow client for sampled visitors
+func (c *ShadowClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	return c.getModifications(visitorID, anonymousID, context, nil)
+}
+
+// GetModificationsWithConsent gets modifications like GetModifications, passing the visitor consent to the primary and shadow clients
+func (c *ShadowClient) GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	return c.getModifications(visitorID, anonymousID, context, &hasConsented)
+}
+
+func (c *ShadowClient) getModifications(visitorID string, anonymousID *string, context model.Context, hasConsented *bool) (*model.APIClientResponse, error) {
+	resp, err := getModifications(c.primary, visitorID, anonymousID, context, hasConsented)
+	if err != nil || !c.isSampled(visitorID) {
+		return resp, err
+	}
+
+	// The wait group is incremented under the close lock so that Close waits for every started comparison
+	c.closeMux.RLock()
+	defer c.closeMux.RUnlock()
+	if c.closed {
+		return resp, nil
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+	default:
+		atomic.AddInt64(&c.dropped, 1)
+		shadowLogger.Debugf("Too many shadow comparisons running. Skipping comparison for visitor %s", visitorID)
+		return resp, nil
+	}
+
+	contextCopy := copyContext(context)
+	anonymousIDCopy := copyAnonymousID(anonymousID)
+
+	c.wg.Add(1)
+	go func() {
+		defer func() {
+			<-c.slots
+			c.wg.Done()
+		}()
+		c.compare(visitorID, anonymousIDCopy, contextCopy, hasConsented, resp)
+	}()
+
+	return resp, nil
+}
+
+// Close stops the comparison of the next decisions, and waits for the running comparisons to be over
+func (c *ShadowClient) Close() {
+	c.closeMux.Lock()
+	c.closed = true
+	c.closeMux.Unlock()
+
+	c.wg.Wait()
+}
+
+func (c *ShadowClient) compare(visitorID string, anonymousID *string, context model.Context, hasConsented *bool, primaryResp *model.APIClientResponse) {
+	shadowResp, err := getModifications(c.shadow, visitorID, anonymousID, context, hasConsented)
+	if err != nil {
+		atomic.AddInt64(&c.errors, 1)
+		shadowLogger.Warnf("Shadow decision failed for visitor %s: %v", visitorID, err)
+		return
+	}
+
+	atomic.AddInt64(&c.compared, 1)
+	differences := CompareResponses(primaryResp, shadowResp)
+	if len(differences) == 0 {
+		return
+	}
+
+	atomic.AddInt64(&c.mismatches, 1)
+	shadowLogger.Infof("Shadow decision mismatch for visitor %s: %d difference(s)", visitorID, len(differences))
+	if c.onMismatch != nil {
+		c.onMismatch(ShadowMismatch{
+			VisitorID:       visitorID,
+			Context:         context,
+			Differences:     differences,
+			PrimaryResponse: primaryResp,
+			ShadowResponse:  shadowResp,
+		})
+	}
+}
+
+// GetPrimaryClient returns the client serving the decisions
+func (c *ShadowClient) GetPrimaryClient() ClientInterface {
+	return c.primary
+}
+
+// GetStats returns the counters of the shadow comparison
+func (c *ShadowClient) GetStats() ShadowStats {
+	return ShadowStats{
+		Compared:   atomic.LoadInt64(&c.compared),
+		Mismatches: atomic.LoadInt64(&c.mismatches),
+		Errors:     atomic.LoadInt64(&c.errors),
+		Dropped:    atomic.LoadInt64(&c.dropped),
+	}
+}
+
+// GetConfigVersion returns the configuration version of the primary client if it knows the targeting
+func (c *ShadowClient) GetConfigVersion() int64 {
+	return getConfigVersion(c.primary)
+}
+
+// IsTargetingContextKey checks the context key against the primary client targeting. Every key is considered targeted if the primary client does not know the targeting
+func (c *ShadowClient) IsTargetingContextKey(key string) bool {
+	return isTargetingContextKey(c.primary, key)
+}
+
+// CompareResponses returns the differences of campaigns, variations and flag values between two decision responses
+func CompareResponses(primary *model.APIClientResponse, shadow *model.APIClientResponse) []ShadowDifference {
+	differences := []ShadowDifference{}
+
+	primaryCampaigns := map[string]model.Campaign{}
+	if primary != nil {
+		for _, campaign := range primary.Campaigns {
+			primaryCampaigns[campaign.ID] = campaign
+		}
+	}
+	shadowCampaigns := map[string]model.Campaign{}
+	if shadow != nil {
+		for _, campaign := range shadow.Campaigns {
+			shadowCampaigns[campaign.ID] = campaign
+		}
+	}
+
+	for id, p := range primaryCampaigns {
+		s, ok := shadowCampaigns[id]
+		if !ok {
+			differences = append(differences, ShadowDifference{CampaignID: id, Field: "campaign", Primary: id})
+			continue
+		}
+		if p.VariationGroupID != s.VariationGroupID {
+			differences = append(differences, ShadowDifference{CampaignID: id, Field: "variationGroupId", Primary: p.VariationGroupID, Shadow: s.VariationGroupID})
+		}
+		if p.Variation.ID != s.Variation.ID {
+			differences = append(differences, ShadowDifference{CampaignID: id, Field: "variationId", Primary: p.Variation.ID, Shadow: s.Variation.ID})
+		}
+		for key, pValue := range p.Variation.Modifications.Value {
+			sValue, ok := s.Variation.Modifications.Value[key]
+			if !ok || !reflect.DeepEqual(pValue, sValue) {
+				differences = append(differences, ShadowDifference{CampaignID: id, Field: fmt.Sprintf("flag:%s", key), Primary: pValue, Shadow: sValue})
+			}
+		}
+		for key, sValue := range s.Variation.Modifications.Value {
+			if _, ok := p.Variation.Modifications.Value[key]; !ok {
+				differences = append(differences, ShadowDifference{CampaignID: id, Field: fmt.Sprintf("flag:%s", key), Shadow: sValue})
+			}
+		}
+	}
+
+	for id := range shadowCampaigns {
+		if _, ok := primaryCampaigns[id]; !ok {
+			differences = append(differences, ShadowDifference{CampaignID: id, Field: "campaign", Shadow: id})
+		}
+	}
+
+	sort.Slice(differences, func(i, j int) bool {
+		if differences[i].CampaignID != differences[j].CampaignID {
+			return differences[i].CampaignID < differences[j].CampaignID
+		}
+		return differences[i].Field < differences[j].Field
+	})
+
+	return differences
+}
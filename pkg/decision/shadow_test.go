@@ -0,0 +1,163 @@
+package decision
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func createShadowResponse(variationID string, flagValue interface{}) *model.APIClientResponse {
+	return &model.APIClientResponse{
+		VisitorID: "test_vid",
+		Campaigns: []model.Campaign{{
+			ID:               "cid",
+			VariationGroupID: "vgid",
+			Variation: model.ClientVariation{
+				ID: variationID,
+				Modifications: model.Modification{
+					Type: "FLAG",
+					Value: map[string]interface{}{
+						"flag": flagValue,
+					},
+				},
+			},
+		}},
+	}
+}
+
+func TestCompareResponses(t *testing.T) {
+	differences := CompareResponses(createShadowResponse("vid", "a"), createShadowResponse("vid", "a"))
+	assert.Equal(t, 0, len(differences))
+
+	differences = CompareResponses(createShadowResponse("vid", "a"), createShadowResponse("vid2", "b"))
+	assert.Equal(t, []ShadowDifference{
+		{CampaignID: "cid", Field: "flag:flag", Primary: "a", Shadow: "b"},
+		{CampaignID: "cid", Field: "variationId", Primary: "vid", Shadow: "vid2"},
+	}, differences)
+
+	differences = CompareResponses(createShadowResponse("vid", "a"), &model.APIClientResponse{})
+	assert.Equal(t, []ShadowDifference{{CampaignID: "cid", Field: "campaign", Primary: "cid"}}, differences)
+
+	differences = CompareResponses(nil, createShadowResponse("vid", "a"))
+	assert.Equal(t, []ShadowDifference{{CampaignID: "cid", Field: "campaign", Shadow: "cid"}}, differences)
+}
+
+func TestShadowClient(t *testing.T) {
+	primaryResponse := createShadowResponse("vid", "a")
+	primary := NewAPIClientMock(testEnvID, primaryResponse, 200)
+
+	mismatches := []ShadowMismatch{}
+	mismatchesMux := sync.Mutex{}
+	onMismatch := func(mismatch ShadowMismatch) {
+		mismatchesMux.Lock()
+		defer mismatchesMux.Unlock()
+		mismatches = append(mismatches, mismatch)
+	}
+
+	// Same decisions
+	client := NewShadowClient(primary, NewAPIClientMock(testEnvID, createShadowResponse("vid", "a"), 200), ShadowOptions{
+		SampleRate: 1,
+		OnMismatch: onMismatch,
+	})
+	resp, err := client.GetModifications("test_vid", nil, model.Context{})
+	client.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, primaryResponse, resp)
+	assert.Equal(t, ShadowStats{Compared: 1}, client.GetStats())
+	assert.Equal(t, 0, len(mismatches))
+	assert.Equal(t, primary, client.GetPrimaryClient())
+
+	// Different decisions
+	client = NewShadowClient(primary, NewAPIClientMock(testEnvID, createShadowResponse("vid2", "a"), 200), ShadowOptions{
+		SampleRate: 1,
+		OnMismatch: onMismatch,
+	})
+	resp, err = client.GetModifications("test_vid", nil, model.Context{"key": "value"})
+	client.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, primaryResponse, resp)
+	assert.Equal(t, ShadowStats{Compared: 1, Mismatches: 1}, client.GetStats())
+	assert.Equal(t, 1, len(mismatches))
+	assert.Equal(t, "test_vid", mismatches[0].VisitorID)
+	assert.Equal(t, "value", mismatches[0].Context["key"])
+	assert.Equal(t, "variationId", mismatches[0].Differences[0].Field)
+
+	// Shadow errors do not affect served decision
+	client = NewShadowClient(primary, NewAPIClientMock(testEnvID, nil, 500), ShadowOptions{
+		SampleRate: 1,
+	})
+	resp, err = client.GetModifications("test_vid", nil, model.Context{})
+	client.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, primaryResponse, resp)
+	assert.Equal(t, ShadowStats{Errors: 1}, client.GetStats())
+
+	// Primary errors are not compared
+	client = NewShadowClient(NewAPIClientMock(testEnvID, nil, 500), primary, ShadowOptions{
+		SampleRate: 1,
+	})
+	_, err = client.GetModifications("test_vid", nil, model.Context{})
+	client.Close()
+	assert.NotNil(t, err)
+	assert.Equal(t, ShadowStats{}, client.GetStats())
+}
+
+func TestShadowSampling(t *testing.T) {
+	client := NewShadowClient(nil, nil, ShadowOptions{SampleRate: 0})
+	assert.False(t, client.isSampled("test_vid"))
+
+	client = NewShadowClient(nil, nil, ShadowOptions{SampleRate: 1})
+	assert.True(t, client.isSampled("test_vid"))
+
+	client = NewShadowClient(nil, nil, ShadowOptions{SampleRate: 0.25})
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		visitorID := fmt.Sprintf("visitor_%d", i)
+		if client.isSampled(visitorID) {
+			sampled++
+		}
+		assert.Equal(t, client.isSampled(visitorID), client.isSampled(visitorID))
+	}
+	assert.InDelta(t, 2500, sampled, 250)
+}
+
+func TestShadowConcurrency(t *testing.T) {
+	primary := NewAPIClientMock(testEnvID, createShadowResponse("vid", "a"), 200)
+	shadow := &blockingClient{release: make(chan struct{})}
+	client := NewShadowClient(primary, shadow, ShadowOptions{SampleRate: 1, MaxConcurrency: 2})
+
+	// The comparisons are dropped while the maximum of running comparisons is reached
+	for i := 0; i < 5; i++ {
+		_, err := client.GetModifications("test_vid", nil, model.Context{})
+		assert.Nil(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&shadow.calls) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, ShadowStats{Dropped: 3}, client.GetStats())
+
+	// Close waits for the running comparisons, and no comparison is started after
+	closed := make(chan struct{})
+	go func() {
+		client.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Error("Close should wait for the running comparisons")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(shadow.release)
+	<-closed
+	assert.Equal(t, ShadowStats{Compared: 2, Mismatches: 2, Dropped: 3}, client.GetStats())
+
+	_, err := client.GetModifications("test_vid", nil, model.Context{})
+	assert.Nil(t, err)
+	client.Close()
+	assert.Equal(t, int64(2), atomic.LoadInt64(&shadow.calls))
+}
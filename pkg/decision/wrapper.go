@@ -0,0 +1,69 @@
+package decision
+
+import (
+	"fmt"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+// GetModificationsWithConsent gets modifications from the client, passing the visitor consent if the client uses it
+func GetModificationsWithConsent(client ClientInterface, visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	return getModifications(client, visitorID, anonymousID, context, &hasConsented)
+}
+
+// getModifications gets modifications from the client, passing the visitor consent if it is known and if the client uses it
+func getModifications(client ClientInterface, visitorID string, anonymousID *string, context model.Context, hasConsented *bool) (*model.APIClientResponse, error) {
+	if consentClient, ok := client.(ConsentClientInterface); ok && hasConsented != nil {
+		return consentClient.GetModificationsWithConsent(visitorID, anonymousID, context, *hasConsented)
+	}
+	return client.GetModifications(visitorID, anonymousID, context)
+}
+
+// getConfigVersion returns the configuration version of the client if it knows the targeting
+func getConfigVersion(client ClientInterface) int64 {
+	if targetingInfo, ok := client.(TargetingInfoInterface); ok {
+		return targetingInfo.GetConfigVersion()
+	}
+	return 0
+}
+
+// isTargetingContextKey checks the context key against the client targeting. Every key is considered targeted if the client does not know the targeting
+func isTargetingContextKey(client ClientInterface, key string) bool {
+	if targetingInfo, ok := client.(TargetingInfoInterface); ok {
+		return targetingInfo.IsTargetingContextKey(key)
+	}
+	return true
+}
+
+// getRequestKey computes a key identifying the decision request of the visitor and its consent for the current configuration of the client
+func getRequestKey(client ClientInterface, visitorID string, anonymousID *string, context model.Context, hasConsented *bool) (string, error) {
+	key, err := hashDecisionRequest(visitorID, anonymousID, context)
+	if err != nil {
+		return "", err
+	}
+
+	if hasConsented != nil {
+		key = fmt.Sprintf("%t:%s", *hasConsented, key)
+	}
+
+	if _, ok := client.(TargetingInfoInterface); ok {
+		key = fmt.Sprintf("%d:%s", getConfigVersion(client), key)
+	}
+	return key, nil
+}
+
+func copyAnonymousID(anonymousID *string) *string {
+	if anonymousID == nil {
+		return nil
+	}
+	id := *anonymousID
+	return &id
+}
+
+func copyContext(context model.Context) model.Context {
+	contextCopy := model.Context{}
+	for k, v := range context {
+		contextCopy[k] = v
+	}
+	return contextCopy
+}
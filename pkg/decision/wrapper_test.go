@@ -0,0 +1,79 @@
+package decision
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type consentClient struct {
+	countingClient
+	mux      sync.Mutex
+	consents []*bool
+}
+
+func (c *consentClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	c.mux.Lock()
+	c.consents = append(c.consents, nil)
+	c.mux.Unlock()
+	return c.countingClient.GetModifications(visitorID, anonymousID, context)
+}
+
+func (c *consentClient) GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	c.mux.Lock()
+	c.consents = append(c.consents, &hasConsented)
+	c.mux.Unlock()
+	return c.countingClient.GetModifications(visitorID, anonymousID, context)
+}
+
+func (c *consentClient) getConsents() []*bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.consents
+}
+
+func TestGetModificationsWithConsent(t *testing.T) {
+	hasConsented, hasNotConsented := true, false
+
+	// The consent is only passed to the clients using it
+	client := &consentClient{}
+	_, err := GetModificationsWithConsent(client, "test_vid", nil, model.Context{}, false)
+	assert.Nil(t, err)
+	_, err = GetModificationsWithConsent(&countingClient{}, "test_vid", nil, model.Context{}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []*bool{&hasNotConsented}, client.getConsents())
+
+	// The wrappers pass the consent to the wrapped clients
+	client = &consentClient{}
+	cachedClient := NewCachedClient(client, CacheOptions{})
+	_, _ = cachedClient.GetModificationsWithConsent("test_vid", nil, model.Context{}, true)
+	_, _ = cachedClient.GetModificationsWithConsent("test_vid", nil, model.Context{}, false)
+	_, _ = cachedClient.GetModificationsWithConsent("test_vid", nil, model.Context{}, false)
+	_, _ = cachedClient.GetModifications("test_vid", nil, model.Context{})
+	assert.Equal(t, []*bool{&hasConsented, &hasNotConsented, nil}, client.getConsents())
+
+	client = &consentClient{}
+	_, _ = NewCoalescingClient(client).GetModificationsWithConsent("test_vid", nil, model.Context{}, false)
+	assert.Equal(t, []*bool{&hasNotConsented}, client.getConsents())
+
+	fallback := &consentClient{}
+	hybridClient := NewHybridClient(&consentClient{countingClient: countingClient{fail: true}}, fallback, HybridOptions{})
+	_, _ = hybridClient.GetModificationsWithConsent("test_vid", nil, model.Context{}, false)
+	assert.Equal(t, []*bool{&hasNotConsented}, fallback.getConsents())
+
+	// The shadow client compares the decisions with the same consent, which is not part of the mismatch context
+	client = &consentClient{}
+	shadow := &consentClient{countingClient: countingClient{calls: 1}}
+	mismatches := []ShadowMismatch{}
+	shadowClient := NewShadowClient(client, shadow, ShadowOptions{
+		SampleRate: 1,
+		OnMismatch: func(mismatch ShadowMismatch) { mismatches = append(mismatches, mismatch) },
+	})
+	_, _ = shadowClient.GetModificationsWithConsent("test_vid", nil, model.Context{"key": "value"}, false)
+	shadowClient.Close()
+	assert.Equal(t, []*bool{&hasNotConsented}, shadow.getConsents())
+	assert.Equal(t, 1, len(mismatches))
+	assert.Equal(t, model.Context{"key": "value"}, mismatches[0].Context)
+}
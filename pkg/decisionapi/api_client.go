@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
 	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
@@ -27,6 +28,8 @@ type APIClient struct {
 	retries           int
 	additionalHeaders map[string]string
 	httpClient        utils.HTTPClientInterface
+	cacheManager      cache.Manager
+	maxCacheStaleness time.Duration
 }
 
 // APIVersionNumber specifies the version of the Decision API to use
@@ -86,6 +89,20 @@ func AdditionalHeaders(headers map[string]string) func(r *APIClient) {
 	}
 }
 
+// CacheManager saves the last successful decision of each visitor in the cache manager, and serves it when the Decision API is unreachable
+func CacheManager(cacheManager cache.Manager) func(r *APIClient) {
+	return func(r *APIClient) {
+		r.cacheManager = cacheManager
+	}
+}
+
+// MaxCacheStaleness sets the maximum age of a cached decision to be served. If 0, cached decisions are always served
+func MaxCacheStaleness(maxCacheStaleness time.Duration) func(r *APIClient) {
+	return func(r *APIClient) {
+		r.maxCacheStaleness = maxCacheStaleness
+	}
+}
+
 // NewAPIClient creates a Decision API client from the environment ID and option builders
 func NewAPIClient(envID string, apiKey string, params ...func(*APIClient)) (*APIClient, error) {
 	res := APIClient{
@@ -127,13 +144,48 @@ func NewAPIClient(envID string, apiKey string, params ...func(*APIClient)) (*API
 	return &res, nil
 }
 
-// GetModifications gets modifications from Decision API
+// GetModifications gets modifications from Decision API, or from the visitor cache if the Decision API is unreachable
 func (r *APIClient) GetModifications(visitorID string, anonymousID *string, context model.Context) (*model.APIClientResponse, error) {
+	return r.getModifications(visitorID, anonymousID, context, nil)
+}
+
+// GetModificationsWithConsent gets modifications like GetModifications, and sends the visitor consent to the Decision API.
+// The visitor cache is not saved without consent
+func (r *APIClient) GetModificationsWithConsent(visitorID string, anonymousID *string, context model.Context, hasConsented bool) (*model.APIClientResponse, error) {
+	return r.getModifications(visitorID, anonymousID, context, &hasConsented)
+}
+
+func (r *APIClient) getModifications(visitorID string, anonymousID *string, context model.Context, hasConsented *bool) (*model.APIClientResponse, error) {
+	resp, err := r.getAPIModifications(visitorID, anonymousID, context, hasConsented)
+	if r.cacheManager == nil {
+		return resp, err
+	}
+
+	if err == nil {
+		// A panic mode response contains no campaigns, and must not replace the cached decision of the visitor
+		if !resp.Panic && (hasConsented == nil || *hasConsented) {
+			r.saveCache(visitorID, resp)
+		}
+		return resp, nil
+	}
+
+	cachedResp, cacheErr := r.getCachedResponse(visitorID)
+	if cacheErr != nil {
+		apiLogger.Infof("No visitor cache to serve: %v", cacheErr)
+		return nil, err
+	}
+
+	apiLogger.Warnf("Decision API call failed: %v. Serving cached decision", err)
+	return cachedResp, nil
+}
+
+func (r *APIClient) getAPIModifications(visitorID string, anonymousID *string, context model.Context, hasConsented *bool) (*model.APIClientResponse, error) {
 	b, err := json.Marshal(model.APIClientRequest{
-		VisitorID:   visitorID,
-		AnonymousID: anonymousID,
-		Context:     context,
-		TriggerHit:  false,
+		VisitorID:      visitorID,
+		AnonymousID:    anonymousID,
+		Context:        context,
+		TriggerHit:     false,
+		VisitorConsent: hasConsented,
 	})
 
 	if err != nil {
@@ -160,6 +212,7 @@ func (r *APIClient) GetModifications(visitorID string, anonymousID *string, cont
 	if err != nil {
 		return nil, err
 	}
+	resp.Source = model.SOURCE_DECISION_API
 
 	return resp, nil
 }
@@ -127,3 +127,56 @@ func TestSendEvent(t *testing.T) {
 
 	assert.Nil(t, err, "Did not expect error for correct activation request")
 }
+
+func TestGetModificationsSource(t *testing.T) {
+	client, _ := NewAPIClient(testEnvID, testAPIKey)
+	responseJSON, _ := json.Marshal(&model.APIClientResponse{VisitorID: "vis_id"})
+	client.httpClient = utils.NewHTTPClientMock(200, responseJSON, nil)
+
+	resp, err := client.GetModifications("test_vid", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, model.SOURCE_DECISION_API, resp.Source)
+}
+
+type recordingHTTPClient struct {
+	utils.HTTPClientInterface
+	body []byte
+}
+
+func (r *recordingHTTPClient) Call(path, method string, body []byte, headers map[string]string) (*utils.HTTPResponse, error) {
+	r.body = body
+	return r.HTTPClientInterface.Call(path, method, body, headers)
+}
+
+func TestGetModificationsConsent(t *testing.T) {
+	cacheManager, cacheCampaignsVisitors := createTestCacheManager()
+	client, _ := NewAPIClient(testEnvID, testAPIKey, CacheManager(cacheManager))
+	responseJSON, _ := json.Marshal(&model.APIClientResponse{
+		VisitorID: "vis_id",
+		Campaigns: []model.Campaign{{ID: "cid", VariationGroupID: "vgid", Variation: model.ClientVariation{ID: "vid"}}},
+	})
+	httpClient := &recordingHTTPClient{HTTPClientInterface: utils.NewHTTPClientMock(200, responseJSON, nil)}
+	client.httpClient = httpClient
+
+	_, err := client.GetModificationsWithConsent("test_vid", nil, model.Context{"key": "value"}, false)
+	assert.Nil(t, err)
+
+	request := model.APIClientRequest{}
+	_ = json.Unmarshal(httpClient.body, &request)
+	assert.Equal(t, false, *request.VisitorConsent)
+	assert.Equal(t, model.Context{"key": "value"}, request.Context)
+
+	// The visitor cache is not saved without consent
+	assert.Nil(t, cacheCampaignsVisitors["test_vid"])
+
+	_, err = client.GetModificationsWithConsent("test_vid", nil, model.Context{}, true)
+	assert.Nil(t, err)
+	_ = json.Unmarshal(httpClient.body, &request)
+	assert.Equal(t, true, *request.VisitorConsent)
+	assert.NotNil(t, cacheCampaignsVisitors["test_vid"])
+
+	_, _ = client.GetModifications("test_vid", nil, nil)
+	request = model.APIClientRequest{}
+	_ = json.Unmarshal(httpClient.body, &request)
+	assert.Nil(t, request.VisitorConsent)
+}
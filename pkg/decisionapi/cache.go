@@ -0,0 +1,89 @@
+package decisionapi
+
+import (
+	"errors"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+// saveCache persists the decision response of the visitor in the cache manager, keeping the activation status of the campaigns
+func (r *APIClient) saveCache(visitorID string, resp *model.APIClientResponse) {
+	existingCache, _ := r.cacheManager.Get(visitorID)
+	now := time.Now()
+
+	campaignsCache := map[string]*cache.CampaignCache{}
+	for _, c := range resp.Campaigns {
+		keys := make([]string, 0, len(c.Variation.Modifications.Value))
+		for k := range c.Variation.Modifications.Value {
+			keys = append(keys, k)
+		}
+
+		alreadyActivated := false
+		if existing, ok := existingCache[c.ID]; ok && existing != nil && existing.VariationID == c.Variation.ID {
+			alreadyActivated = existing.Activated
+		}
+
+		campaignsCache[c.ID] = &cache.CampaignCache{
+			VariationGroupID: c.VariationGroupID,
+			VariationID:      c.Variation.ID,
+			Activated:        alreadyActivated,
+			FlagKeys:         keys,
+			IsReference:      c.Variation.Reference,
+			ModificationType: c.Variation.Modifications.Type,
+			FlagValues:       c.Variation.Modifications.Value,
+			UpdatedAt:        &now,
+		}
+	}
+
+	err := r.cacheManager.Set(visitorID, campaignsCache)
+	if err != nil {
+		apiLogger.Warnf("Cache saving failed: %v", err)
+	}
+}
+
+// getCachedResponse builds the decision response of the visitor from the cache manager, if it is not too stale
+// and contains at least one campaign saved by the Decision API client
+func (r *APIClient) getCachedResponse(visitorID string) (*model.APIClientResponse, error) {
+	campaignsCache, err := r.cacheManager.Get(visitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.APIClientResponse{
+		VisitorID: visitorID,
+		Campaigns: []model.Campaign{},
+		Source:    model.SOURCE_CACHE,
+	}
+
+	for id, c := range campaignsCache {
+		// Campaigns cached by the bucketing engine do not contain the flag values
+		if c == nil || c.UpdatedAt == nil || c.FlagValues == nil {
+			continue
+		}
+
+		if r.maxCacheStaleness > 0 && time.Since(*c.UpdatedAt) > r.maxCacheStaleness {
+			return nil, errors.New("Visitor cache is too stale")
+		}
+
+		resp.Campaigns = append(resp.Campaigns, model.Campaign{
+			ID:               id,
+			VariationGroupID: c.VariationGroupID,
+			Variation: model.ClientVariation{
+				ID:        c.VariationID,
+				Reference: c.IsReference,
+				Modifications: model.Modification{
+					Type:  c.ModificationType,
+					Value: c.FlagValues,
+				},
+			},
+		})
+	}
+
+	if len(resp.Campaigns) == 0 {
+		return nil, errors.New("Visitor cache contains no decision")
+	}
+
+	return resp, nil
+}
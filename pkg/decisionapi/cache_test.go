@@ -0,0 +1,133 @@
+package decisionapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/cache"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestCacheManager() (cache.Manager, map[string]map[string]*cache.CampaignCache) {
+	cacheCampaignsVisitors := map[string]map[string]*cache.CampaignCache{}
+	get := func(visitorID string) (map[string]*cache.CampaignCache, error) {
+		return cacheCampaignsVisitors[visitorID], nil
+	}
+	set := func(visitorID string, cache map[string]*cache.CampaignCache) error {
+		cacheCampaignsVisitors[visitorID] = cache
+		return nil
+	}
+	cacheManager, _ := cache.InitManager(cache.WithCustomOptions(cache.CustomOptions{
+		Getter: get,
+		Setter: set,
+	}))
+	return cacheManager, cacheCampaignsVisitors
+}
+
+func TestGetModificationsCache(t *testing.T) {
+	cacheManager, cacheCampaignsVisitors := createTestCacheManager()
+
+	client, _ := NewAPIClient(testEnvID, testAPIKey, CacheManager(cacheManager), MaxCacheStaleness(time.Hour))
+	assert.Equal(t, time.Hour, client.maxCacheStaleness)
+
+	// No cache to serve
+	client.httpClient = utils.NewHTTPClientMock(500, nil, nil)
+	_, err := client.GetModifications("test_vid", nil, nil)
+	assert.NotNil(t, err)
+
+	response := &model.APIClientResponse{
+		VisitorID: "test_vid",
+		Campaigns: []model.Campaign{{
+			ID:               "cid",
+			VariationGroupID: "vgid",
+			Variation: model.ClientVariation{
+				ID:        "vid",
+				Reference: true,
+				Modifications: model.Modification{
+					Type: "FLAG",
+					Value: map[string]interface{}{
+						"flag": "value",
+					},
+				},
+			},
+		}},
+	}
+	responseJSON, _ := json.Marshal(response)
+	client.httpClient = utils.NewHTTPClientMock(200, responseJSON, nil)
+
+	resp, err := client.GetModifications("test_vid", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, model.SOURCE_DECISION_API, resp.Source)
+	assert.Equal(t, "vid", cacheCampaignsVisitors["test_vid"]["cid"].VariationID)
+	assert.Equal(t, "value", cacheCampaignsVisitors["test_vid"]["cid"].FlagValues["flag"])
+
+	// Activation status is kept
+	cacheCampaignsVisitors["test_vid"]["cid"].Activated = true
+	_, _ = client.GetModifications("test_vid", nil, nil)
+	assert.True(t, cacheCampaignsVisitors["test_vid"]["cid"].Activated)
+
+	// Cached decision is served when the API fails
+	client.httpClient = utils.NewHTTPClientMock(500, nil, nil)
+	resp, err = client.GetModifications("test_vid", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, model.SOURCE_CACHE, resp.Source)
+	assert.Equal(t, 1, len(resp.Campaigns))
+	assert.Equal(t, "cid", resp.Campaigns[0].ID)
+	assert.Equal(t, "vgid", resp.Campaigns[0].VariationGroupID)
+	assert.Equal(t, "vid", resp.Campaigns[0].Variation.ID)
+	assert.True(t, resp.Campaigns[0].Variation.Reference)
+	assert.Equal(t, "value", resp.Campaigns[0].Variation.Modifications.Value["flag"])
+
+	// Stale cache is not served
+	staleDate := time.Now().Add(-2 * time.Hour)
+	cacheCampaignsVisitors["test_vid"]["cid"].UpdatedAt = &staleDate
+	_, err = client.GetModifications("test_vid", nil, nil)
+	assert.NotNil(t, err)
+
+	// Campaigns cached without flag values are skipped, and the API error is returned when no campaign is usable
+	cacheCampaignsVisitors["test_vid"]["cid"] = &cache.CampaignCache{VariationGroupID: "vgid", VariationID: "vid"}
+	resp, err = client.GetModifications("test_vid", nil, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestGetModificationsCachePanic(t *testing.T) {
+	cacheManager, cacheCampaignsVisitors := createTestCacheManager()
+	client, _ := NewAPIClient(testEnvID, testAPIKey, CacheManager(cacheManager))
+
+	response := &model.APIClientResponse{
+		VisitorID: "test_vid",
+		Campaigns: []model.Campaign{{
+			ID:               "cid",
+			VariationGroupID: "vgid",
+			Variation: model.ClientVariation{
+				ID: "vid",
+				Modifications: model.Modification{
+					Type:  "FLAG",
+					Value: map[string]interface{}{"flag": "value"},
+				},
+			},
+		}},
+	}
+	responseJSON, _ := json.Marshal(response)
+	client.httpClient = utils.NewHTTPClientMock(200, responseJSON, nil)
+	_, err := client.GetModifications("test_vid", nil, nil)
+	assert.Nil(t, err)
+
+	// The panic mode response does not replace the cached decision
+	panicJSON, _ := json.Marshal(&model.APIClientResponse{VisitorID: "test_vid", Panic: true})
+	client.httpClient = utils.NewHTTPClientMock(200, panicJSON, nil)
+	resp, err := client.GetModifications("test_vid", nil, nil)
+	assert.Nil(t, err)
+	assert.True(t, resp.Panic)
+	assert.Equal(t, 1, len(cacheCampaignsVisitors["test_vid"]))
+
+	client.httpClient = utils.NewHTTPClientMock(500, nil, nil)
+	resp, err = client.GetModifications("test_vid", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, model.SOURCE_CACHE, resp.Source)
+	assert.Equal(t, 1, len(resp.Campaigns))
+}
@@ -0,0 +1,90 @@
+package extractor
+
+import (
+	"net/http"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+var extractorLogger = logging.CreateLogger("FS Context Extractor")
+
+// Extractor adds the visitor context keys extracted from an HTTP request.
+// The context contains the keys added by the previous extractors of the pipeline
+type Extractor interface {
+	Extract(r *http.Request, context model.Context)
+}
+
+// ExtractorFunc is a func type implementing the Extractor interface
+type ExtractorFunc func(r *http.Request, context model.Context)
+
+// Extract calls the extractor func
+func (f ExtractorFunc) Extract(r *http.Request, context model.Context) {
+	f(r, context)
+}
+
+// ContextExtractor builds a visitor context from an HTTP request by running a pipeline of extractors
+type ContextExtractor struct {
+	extractors []Extractor
+}
+
+// NewContextExtractor creates a context extractor running the extractors in order
+func NewContextExtractor(extractors ...Extractor) *ContextExtractor {
+	return &ContextExtractor{
+		extractors: extractors,
+	}
+}
+
+// Use adds extractors at the end of the pipeline
+func (e *ContextExtractor) Use(extractors ...Extractor) *ContextExtractor {
+	e.extractors = append(e.extractors, extractors...)
+	return e
+}
+
+// Extract returns the visitor context built from the request. The keys of the last extractors take precedence
+func (e *ContextExtractor) Extract(r *http.Request) model.Context {
+	context := model.Context{}
+	if r == nil {
+		extractorLogger.Warn("Cannot extract visitor context from a nil request")
+		return context
+	}
+
+	for _, extractor := range e.extractors {
+		extractor.Extract(r, context)
+	}
+	return context
+}
+
+// Headers extracts the request headers to the context keys of the mapping (header name => context key)
+func Headers(mapping map[string]string) Extractor {
+	return ExtractorFunc(func(r *http.Request, context model.Context) {
+		for header, key := range mapping {
+			if value := r.Header.Get(header); value != "" {
+				context[key] = value
+			}
+		}
+	})
+}
+
+// Cookies extracts the request cookies to the context keys of the mapping (cookie name => context key)
+func Cookies(mapping map[string]string) Extractor {
+	return ExtractorFunc(func(r *http.Request, context model.Context) {
+		for name, key := range mapping {
+			if cookie, err := r.Cookie(name); err == nil && cookie.Value != "" {
+				context[key] = cookie.Value
+			}
+		}
+	})
+}
+
+// QueryParams extracts the request query parameters to the context keys of the mapping (parameter name => context key)
+func QueryParams(mapping map[string]string) Extractor {
+	return ExtractorFunc(func(r *http.Request, context model.Context) {
+		query := r.URL.Query()
+		for param, key := range mapping {
+			if value := query.Get(param); value != "" {
+				context[key] = value
+			}
+		}
+	})
+}
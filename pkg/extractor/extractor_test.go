@@ -0,0 +1,101 @@
+package extractor
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// readRecordedRequest reads a raw HTTP request recorded in the testdata folder
+func readRecordedRequest(t *testing.T, name string, remoteAddr string) *http.Request {
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	r, err := http.ReadRequest(bufio.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.RemoteAddr = remoteAddr
+	return r
+}
+
+func createTestExtractor(t *testing.T) *ContextExtractor {
+	clientIP, err := ClientIP("10.0.0.0/8", "203.0.113.10")
+	assert.Nil(t, err)
+
+	return NewContextExtractor(UserAgent(), AcceptLanguage(), clientIP).Use(
+		Headers(map[string]string{"X-Country": "country"}),
+		Cookies(map[string]string{"plan_tier": "plan"}),
+		QueryParams(map[string]string{"utm_source": "utm_source"}),
+	)
+}
+
+func TestExtractRecordedRequests(t *testing.T) {
+	extractor := createTestExtractor(t)
+
+	context := extractor.Extract(readRecordedRequest(t, "chrome_desktop.http", "192.0.2.44:52100"))
+	assert.Equal(t, model.Context{
+		BROWSER_CONTEXT_KEY:         "Chrome",
+		BROWSER_VERSION_CONTEXT_KEY: "118.0.5993.88",
+		OS_CONTEXT_KEY:              "Windows",
+		DEVICE_TYPE_CONTEXT_KEY:     DEVICE_TYPE_DESKTOP,
+		LANGUAGE_CONTEXT_KEY:        "fr-FR",
+		IP_CONTEXT_KEY:              "192.0.2.44",
+		"country":                   "FR",
+		"plan":                      "gold",
+		"utm_source":                "newsletter",
+	}, context)
+	assert.Empty(t, context.Validate())
+
+	context = extractor.Extract(readRecordedRequest(t, "safari_iphone_proxied.http", "10.0.0.1:443"))
+	assert.Equal(t, model.Context{
+		BROWSER_CONTEXT_KEY:         "Safari",
+		BROWSER_VERSION_CONTEXT_KEY: "17.0",
+		OS_CONTEXT_KEY:              "iOS",
+		DEVICE_TYPE_CONTEXT_KEY:     DEVICE_TYPE_MOBILE,
+		LANGUAGE_CONTEXT_KEY:        "de-DE",
+		IP_CONTEXT_KEY:              "198.51.100.7",
+	}, context)
+
+	context = extractor.Extract(readRecordedRequest(t, "samsung_tablet.http", "192.0.2.45:52100"))
+	assert.Equal(t, model.Context{
+		BROWSER_CONTEXT_KEY:         "Samsung Internet",
+		BROWSER_VERSION_CONTEXT_KEY: "22.0",
+		OS_CONTEXT_KEY:              "Android",
+		DEVICE_TYPE_CONTEXT_KEY:     DEVICE_TYPE_TABLET,
+		IP_CONTEXT_KEY:              "192.0.2.45",
+	}, context)
+
+	// The X-Forwarded-For header of an untrusted client is ignored
+	context = extractor.Extract(readRecordedRequest(t, "googlebot.http", "66.249.66.200:40000"))
+	assert.Equal(t, model.Context{
+		DEVICE_TYPE_CONTEXT_KEY: DEVICE_TYPE_BOT,
+		IP_CONTEXT_KEY:          "66.249.66.200",
+	}, context)
+}
+
+func TestExtractorPipeline(t *testing.T) {
+	extractor := NewContextExtractor(
+		Headers(map[string]string{"X-Plan": "plan"}),
+		ExtractorFunc(func(r *http.Request, context model.Context) {
+			if context["plan"] == "premium" {
+				context["is_premium"] = true
+			}
+		}),
+	)
+
+	r, _ := http.NewRequest("GET", "http://example.com", nil)
+	r.Header.Set("X-Plan", "premium")
+	assert.Equal(t, model.Context{"plan": "premium", "is_premium": true}, extractor.Extract(r))
+
+	assert.Equal(t, model.Context{}, extractor.Extract(nil))
+	assert.Equal(t, model.Context{}, NewContextExtractor().Extract(r))
+}
@@ -0,0 +1,95 @@
+package extractor
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+// IP_CONTEXT_KEY is the context key of the visitor IP address
+const IP_CONTEXT_KEY = "ip"
+
+// ClientIP extracts the client IP address of the request. The X-Forwarded-For header is only used
+// when the request comes from one of the trusted proxies, given as IP addresses or CIDR ranges
+func ClientIP(trustedProxies ...string) (Extractor, error) {
+	trustedNetworks, err := parseNetworks(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	return ExtractorFunc(func(r *http.Request, context model.Context) {
+		if ip := getClientIP(r, trustedNetworks); ip != nil {
+			context[IP_CONTEXT_KEY] = ip.String()
+		}
+	}), nil
+}
+
+// parseNetworks parses the IP addresses and CIDR ranges
+func parseNetworks(values []string) ([]*net.IPNet, error) {
+	networks := []*net.IPNet{}
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("Invalid trusted proxy IP address : %s", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid trusted proxy CIDR range : %s", value)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func isTrusted(ip net.IP, trustedNetworks []*net.IPNet) bool {
+	for _, network := range trustedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// getClientIP returns the remote address of the request, or the last address of the X-Forwarded-For chain
+// that is not a trusted proxy, if the remote address is trusted
+func getClientIP(r *http.Request, trustedNetworks []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isTrusted(ip, trustedNetworks) {
+		return ip
+	}
+
+	// The addresses are appended by each proxy, so the chain is read from the right
+	forwardedIPs := []string{}
+	for _, header := range r.Header[http.CanonicalHeaderKey("X-Forwarded-For")] {
+		forwardedIPs = append(forwardedIPs, strings.Split(header, ",")...)
+	}
+	for i := len(forwardedIPs) - 1; i >= 0; i-- {
+		forwardedIP := net.ParseIP(strings.TrimSpace(forwardedIPs[i]))
+		if forwardedIP == nil {
+			extractorLogger.Warn(fmt.Sprintf("Invalid X-Forwarded-For address : %s", forwardedIPs[i]))
+			break
+		}
+		ip = forwardedIP
+		if !isTrusted(ip, trustedNetworks) {
+			break
+		}
+	}
+	return ip
+}
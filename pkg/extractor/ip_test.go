@@ -0,0 +1,43 @@
+package extractor
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	_, err := ClientIP("not_an_ip")
+	assert.NotNil(t, err)
+
+	_, err = ClientIP("10.0.0.0/33")
+	assert.NotNil(t, err)
+
+	extractor, err := ClientIP("10.0.0.1", "2001:db8::/32")
+	assert.Nil(t, err)
+
+	extractIP := func(remoteAddr string, forwardedFor ...string) interface{} {
+		r, _ := http.NewRequest("GET", "http://example.com", nil)
+		r.RemoteAddr = remoteAddr
+		for _, header := range forwardedFor {
+			r.Header.Add("X-Forwarded-For", header)
+		}
+		context := model.Context{}
+		extractor.Extract(r, context)
+		return context[IP_CONTEXT_KEY]
+	}
+
+	assert.Equal(t, "192.0.2.1", extractIP("192.0.2.1:1234", "198.51.100.1"))
+	assert.Equal(t, "198.51.100.1", extractIP("10.0.0.1:1234", "198.51.100.1"))
+	assert.Equal(t, "198.51.100.2", extractIP("[2001:db8::1]:1234", "198.51.100.1, 198.51.100.2", "2001:db8::2"))
+	assert.Equal(t, "10.0.0.1", extractIP("10.0.0.1:1234"))
+
+	// A spoofed address on the left of the chain is ignored
+	assert.Equal(t, "198.51.100.2", extractIP("10.0.0.1:1234", "127.0.0.1, 198.51.100.2"))
+	assert.Equal(t, "10.0.0.1", extractIP("10.0.0.1:1234", "invalid"))
+
+	assert.Equal(t, "192.0.2.1", extractIP("192.0.2.1"))
+	assert.Nil(t, extractIP(""))
+}
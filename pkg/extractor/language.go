@@ -0,0 +1,53 @@
+package extractor
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+// LANGUAGE_CONTEXT_KEY is the context key of the visitor preferred language
+const LANGUAGE_CONTEXT_KEY = "language"
+
+// AcceptLanguage extracts the preferred language of the Accept-Language header, such as "fr-FR"
+func AcceptLanguage() Extractor {
+	return ExtractorFunc(func(r *http.Request, context model.Context) {
+		if language := parseAcceptLanguage(r.Header.Get("Accept-Language")); language != "" {
+			context[LANGUAGE_CONTEXT_KEY] = language
+		}
+	})
+}
+
+// parseAcceptLanguage returns the language with the highest quality value of the header
+func parseAcceptLanguage(header string) string {
+	language := ""
+	bestQuality := 0.
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(params[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					q = 0
+				}
+				quality = q
+			}
+		}
+
+		// The first language is kept for equal quality values
+		if quality > bestQuality {
+			language = tag
+			bestQuality = quality
+		}
+	}
+	return language
+}
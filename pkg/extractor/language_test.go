@@ -0,0 +1,16 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, "fr-FR", parseAcceptLanguage("fr-FR,fr;q=0.9,en;q=0.8"))
+	assert.Equal(t, "en-GB", parseAcceptLanguage("de;q=0.7, en-GB;q=0.8 , fr;q=0.5"))
+	assert.Equal(t, "de", parseAcceptLanguage("en;q=0.5, de"))
+	assert.Equal(t, "es", parseAcceptLanguage("*, es;q=0.1"))
+	assert.Equal(t, "", parseAcceptLanguage("en;q=0"))
+	assert.Equal(t, "", parseAcceptLanguage(""))
+}
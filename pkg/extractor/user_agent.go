@@ -0,0 +1,125 @@
+package extractor
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+// Context keys of the user agent extractor
+const (
+	BROWSER_CONTEXT_KEY         = "browser"
+	BROWSER_VERSION_CONTEXT_KEY = "browser_version"
+	OS_CONTEXT_KEY              = "os"
+	DEVICE_TYPE_CONTEXT_KEY     = "device_type"
+)
+
+// Device types of the user agent extractor
+const (
+	DEVICE_TYPE_DESKTOP = "desktop"
+	DEVICE_TYPE_MOBILE  = "mobile"
+	DEVICE_TYPE_TABLET  = "tablet"
+	DEVICE_TYPE_BOT     = "bot"
+)
+
+// UserAgentInfo represents the information parsed from a user agent
+type UserAgentInfo struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	DeviceType     string
+}
+
+type browserMatcher struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// The browsers are matched in order, as most user agents also contain the tokens of the browsers they derive from
+var browserMatchers = []browserMatcher{
+	{"Edge", regexp.MustCompile(`(?:Edg|EdgA|EdgiOS|Edge)/([\d.]+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/([\d.]+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/([\d.]+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/([\d.]+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/([\d.]+)`)},
+	{"Safari", regexp.MustCompile(`Version/([\d.]+).*Safari/`)},
+	{"Internet Explorer", regexp.MustCompile(`(?:MSIE |Trident/.*rv:)([\d.]+)`)},
+}
+
+var botPattern = regexp.MustCompile(`(?i)bot|crawler|spider|crawling|headless`)
+
+// ParseUserAgent returns the browser, OS and device type of the user agent. Unknown values are left empty
+func ParseUserAgent(userAgent string) UserAgentInfo {
+	info := UserAgentInfo{}
+	if userAgent == "" {
+		return info
+	}
+
+	for _, matcher := range browserMatchers {
+		if matches := matcher.pattern.FindStringSubmatch(userAgent); matches != nil {
+			info.Browser = matcher.name
+			info.BrowserVersion = matches[1]
+			break
+		}
+	}
+
+	info.OS = parseOS(userAgent)
+	info.DeviceType = parseDeviceType(userAgent)
+	return info
+}
+
+func parseOS(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "iPod"):
+		return "iOS"
+	case strings.Contains(userAgent, "Android"):
+		return "Android"
+	case strings.Contains(userAgent, "CrOS"):
+		return "Chrome OS"
+	case strings.Contains(userAgent, "Windows"):
+		return "Windows"
+	case strings.Contains(userAgent, "Mac OS X"), strings.Contains(userAgent, "Macintosh"):
+		return "Mac OS"
+	case strings.Contains(userAgent, "Linux"):
+		return "Linux"
+	}
+	return ""
+}
+
+func parseDeviceType(userAgent string) string {
+	switch {
+	case botPattern.MatchString(userAgent):
+		return DEVICE_TYPE_BOT
+	case strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "Tablet"),
+		strings.Contains(userAgent, "Android") && !strings.Contains(userAgent, "Mobile"):
+		return DEVICE_TYPE_TABLET
+	case strings.Contains(userAgent, "Mobi"), strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPod"):
+		return DEVICE_TYPE_MOBILE
+	}
+	return DEVICE_TYPE_DESKTOP
+}
+
+// UserAgent extracts the browser, browser version, OS and device type of the User-Agent header
+func UserAgent() Extractor {
+	return ExtractorFunc(func(r *http.Request, context model.Context) {
+		userAgent := r.UserAgent()
+		if userAgent == "" {
+			return
+		}
+
+		info := ParseUserAgent(userAgent)
+		values := map[string]string{
+			BROWSER_CONTEXT_KEY:         info.Browser,
+			BROWSER_VERSION_CONTEXT_KEY: info.BrowserVersion,
+			OS_CONTEXT_KEY:              info.OS,
+			DEVICE_TYPE_CONTEXT_KEY:     info.DeviceType,
+		}
+		for key, value := range values {
+			if value != "" {
+				context[key] = value
+			}
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	userAgents := map[string]UserAgentInfo{
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.6 Safari/605.1.15": {
+			Browser: "Safari", BrowserVersion: "16.6", OS: "Mac OS", DeviceType: DEVICE_TYPE_DESKTOP,
+		},
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36 Edg/118.0.2088.46": {
+			Browser: "Edge", BrowserVersion: "118.0.2088.46", OS: "Windows", DeviceType: DEVICE_TYPE_DESKTOP,
+		},
+		"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/119.0": {
+			Browser: "Firefox", BrowserVersion: "119.0", OS: "Linux", DeviceType: DEVICE_TYPE_DESKTOP,
+		},
+		"Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Mobile Safari/537.36 OPR/76.2.4027.73374": {
+			Browser: "Opera", BrowserVersion: "76.2.4027.73374", OS: "Android", DeviceType: DEVICE_TYPE_MOBILE,
+		},
+		"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/118.0.5993.92 Mobile/15E148 Safari/604.1": {
+			Browser: "Chrome", BrowserVersion: "118.0.5993.92", OS: "iOS", DeviceType: DEVICE_TYPE_TABLET,
+		},
+		"Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36": {
+			Browser: "Chrome", BrowserVersion: "118.0.0.0", OS: "Chrome OS", DeviceType: DEVICE_TYPE_DESKTOP,
+		},
+		"Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko": {
+			Browser: "Internet Explorer", BrowserVersion: "11.0", OS: "Windows", DeviceType: DEVICE_TYPE_DESKTOP,
+		},
+		"curl/8.1.2": {
+			DeviceType: DEVICE_TYPE_DESKTOP,
+		},
+		"": {},
+	}
+
+	for userAgent, expected := range userAgents {
+		assert.Equal(t, expected, ParseUserAgent(userAgent), userAgent)
+	}
+}
@@ -0,0 +1,254 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/extractor"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+var logger = logging.CreateLogger("FS Geo Enricher")
+
+// Context keys added by the geo enricher
+const (
+	CONTINENT_CODE_CONTEXT_KEY = "geo_continent_code"
+	COUNTRY_CODE_CONTEXT_KEY   = "geo_country_code"
+	COUNTRY_CONTEXT_KEY        = "geo_country"
+	REGION_CODE_CONTEXT_KEY    = "geo_region_code"
+	REGION_CONTEXT_KEY         = "geo_region"
+	CITY_CONTEXT_KEY           = "geo_city"
+	POSTAL_CODE_CONTEXT_KEY    = "geo_postal_code"
+	TIME_ZONE_CONTEXT_KEY      = "geo_time_zone"
+)
+
+// Location represents the geo location of an IP address
+type Location struct {
+	ContinentCode string
+	CountryCode   string
+	Country       string
+	RegionCode    string
+	Region        string
+	City          string
+	PostalCode    string
+	TimeZone      string
+}
+
+// cityRecord represents the fields of the GeoIP2 and GeoLite2 City records used by the enricher
+type cityRecord struct {
+	Continent struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"continent"`
+	Country struct {
+		IsoCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		IsoCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Postal struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"postal"`
+	Location struct {
+		TimeZone string `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+}
+
+// Enricher adds the geo location keys of the visitor IP to the context, from a local MaxMind DB (mmdb) file
+// such as GeoLite2 City or GeoIP2 City. The file is read in memory and reloaded when it changes
+type Enricher struct {
+	path           string
+	reloadInterval time.Duration
+	language       string
+	db             *maxminddb.Reader
+	modTime        time.Time
+	size           int64
+	dbMux          sync.RWMutex
+	ticker         *time.Ticker
+	stop           chan struct{}
+	closeOnce      sync.Once
+}
+
+// ReloadInterval sets the interval between each check of the database file changes. If -1, then the file is never reloaded
+func ReloadInterval(interval time.Duration) func(*Enricher) {
+	return func(e *Enricher) {
+		e.reloadInterval = interval
+	}
+}
+
+// Language sets the language of the location names. Defaults to "en"
+func Language(language string) func(*Enricher) {
+	return func(e *Enricher) {
+		e.language = language
+	}
+}
+
+// NewEnricher creates a geo enricher reading the MaxMind DB file at path
+func NewEnricher(path string, params ...func(*Enricher)) (*Enricher, error) {
+	enricher := &Enricher{
+		path:           path,
+		reloadInterval: 1 * time.Minute,
+		language:       "en",
+		stop:           make(chan struct{}),
+	}
+
+	for _, param := range params {
+		param(enricher)
+	}
+
+	if err := enricher.Reload(); err != nil {
+		return nil, err
+	}
+
+	if enricher.reloadInterval > 0 {
+		enricher.ticker = time.NewTicker(enricher.reloadInterval)
+		go enricher.startTicker()
+	}
+	return enricher, nil
+}
+
+// startTicker reloads the database when the file changes, until the enricher is closed
+func (e *Enricher) startTicker() {
+	for {
+		select {
+		case <-e.ticker.C:
+			if err := e.Reload(); err != nil {
+				logger.Warnf("Geo database reload failed: %v", err)
+			}
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// Reload reads the database file again if its modification time or size changed.
+// The previous database is kept if the new file is invalid
+func (e *Enricher) Reload() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return fmt.Errorf("Error when reading geo database file : %v", err)
+	}
+
+	e.dbMux.RLock()
+	unchanged := e.db != nil && info.ModTime().Equal(e.modTime) && info.Size() == e.size
+	e.dbMux.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	buffer, err := os.ReadFile(e.path)
+	if err != nil {
+		return fmt.Errorf("Error when reading geo database file : %v", err)
+	}
+
+	db, err := maxminddb.FromBytes(buffer)
+	if err != nil {
+		return fmt.Errorf("Error when reading geo database file : %v", err)
+	}
+
+	logger.Info(fmt.Sprintf("Loaded geo database %s of type %s", e.path, db.Metadata.DatabaseType))
+
+	e.dbMux.Lock()
+	defer e.dbMux.Unlock()
+	e.db = db
+	e.modTime = info.ModTime()
+	e.size = info.Size()
+	return nil
+}
+
+// Close stops the database file reloading
+func (e *Enricher) Close() {
+	e.closeOnce.Do(func() {
+		if e.ticker != nil {
+			e.ticker.Stop()
+		}
+		close(e.stop)
+	})
+}
+
+// Lookup returns the geo location of the IP address, or nil if the address is not in the database
+func (e *Enricher) Lookup(ip net.IP) (*Location, error) {
+	e.dbMux.RLock()
+	db := e.db
+	e.dbMux.RUnlock()
+
+	if db == nil {
+		return nil, errors.New("Geo database is not loaded")
+	}
+
+	record := cityRecord{}
+	_, found, err := db.LookupNetwork(ip, &record)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	location := &Location{
+		ContinentCode: record.Continent.Code,
+		CountryCode:   record.Country.IsoCode,
+		Country:       record.Country.Names[e.language],
+		City:          record.City.Names[e.language],
+		PostalCode:    record.Postal.Code,
+		TimeZone:      record.Location.TimeZone,
+	}
+
+	// The first subdivision is the largest one
+	if len(record.Subdivisions) > 0 {
+		location.RegionCode = record.Subdivisions[0].IsoCode
+		location.Region = record.Subdivisions[0].Names[e.language]
+	}
+	return location, nil
+}
+
+// Enrich adds the geo location keys of the IP address to the context. Unknown values are not added
+func (e *Enricher) Enrich(ip string, context model.Context) error {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return fmt.Errorf("Invalid IP address : %s", ip)
+	}
+
+	location, err := e.Lookup(parsedIP)
+	if err != nil || location == nil {
+		return err
+	}
+
+	values := map[string]string{
+		CONTINENT_CODE_CONTEXT_KEY: location.ContinentCode,
+		COUNTRY_CODE_CONTEXT_KEY:   location.CountryCode,
+		COUNTRY_CONTEXT_KEY:        location.Country,
+		REGION_CODE_CONTEXT_KEY:    location.RegionCode,
+		REGION_CONTEXT_KEY:         location.Region,
+		CITY_CONTEXT_KEY:           location.City,
+		POSTAL_CODE_CONTEXT_KEY:    location.PostalCode,
+		TIME_ZONE_CONTEXT_KEY:      location.TimeZone,
+	}
+	for key, value := range values {
+		if value != "" {
+			context[key] = value
+		}
+	}
+	return nil
+}
+
+// Extract adds the geo location keys of the IP address extracted by the previous extractors of the pipeline,
+// so that the enricher can be used after extractor.ClientIP in an extractor.ContextExtractor
+func (e *Enricher) Extract(r *http.Request, context model.Context) {
+	ip, ok := context[extractor.IP_CONTEXT_KEY].(string)
+	if !ok {
+		return
+	}
+
+	if err := e.Enrich(ip, context); err != nil {
+		logger.Warnf("Geo enrichment failed: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package geo
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/extractor"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEnricher(t *testing.T) {
+	_, err := NewEnricher(filepath.Join("testdata", "unknown.mmdb"))
+	assert.NotNil(t, err)
+
+	enricher, err := NewEnricher(testDatabasePath, ReloadInterval(-1))
+	assert.Nil(t, err)
+	defer enricher.Close()
+	assert.Nil(t, enricher.ticker)
+}
+
+func TestEnrich(t *testing.T) {
+	enricher, _ := NewEnricher(testDatabasePath, ReloadInterval(-1))
+	defer enricher.Close()
+
+	context := model.Context{"key": "value"}
+	assert.Nil(t, enricher.Enrich("81.2.69.142", context))
+	assert.Equal(t, model.Context{
+		"key":                      "value",
+		CONTINENT_CODE_CONTEXT_KEY: "EU",
+		COUNTRY_CODE_CONTEXT_KEY:   "GB",
+		COUNTRY_CONTEXT_KEY:        "United Kingdom",
+		REGION_CODE_CONTEXT_KEY:    "ENG",
+		REGION_CONTEXT_KEY:         "England",
+		CITY_CONTEXT_KEY:           "London",
+		POSTAL_CODE_CONTEXT_KEY:    "E1",
+		TIME_ZONE_CONTEXT_KEY:      "Europe/London",
+	}, context)
+	assert.Empty(t, context.Validate())
+
+	// Missing values are not added
+	context = model.Context{}
+	assert.Nil(t, enricher.Enrich("2a02:cf40::1", context))
+	assert.Equal(t, model.Context{COUNTRY_CODE_CONTEXT_KEY: "NO"}, context)
+
+	context = model.Context{}
+	assert.Nil(t, enricher.Enrich("127.0.0.1", context))
+	assert.Equal(t, model.Context{}, context)
+
+	assert.NotNil(t, enricher.Enrich("invalid", context))
+	_, err := enricher.Lookup(nil)
+	assert.NotNil(t, err)
+
+	location, err := enricher.Lookup(net.ParseIP("2001:480:10::1"))
+	assert.Nil(t, err)
+	assert.Equal(t, "San Diego", location.City)
+	assert.Equal(t, "CA", location.RegionCode)
+
+	frEnricher, _ := NewEnricher(testDatabasePath, ReloadInterval(-1), Language("fr"))
+	defer frEnricher.Close()
+	location, _ = frEnricher.Lookup(net.ParseIP("89.160.20.130"))
+	assert.Equal(t, "Suède", location.Country)
+	assert.Equal(t, "", location.City)
+}
+
+func TestEnrichRecordSizes(t *testing.T) {
+	dir, err := os.MkdirTemp("", "geo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// GeoLite2 and GeoIP2 City databases use 28 bits records
+	for _, recordSize := range []int{24, 28, 32} {
+		path := filepath.Join(dir, fmt.Sprintf("geo-%d.mmdb", recordSize))
+		assert.Nil(t, os.WriteFile(path, writeTestDatabase(testNetworks, recordSize), 0644))
+
+		enricher, err := NewEnricher(path, ReloadInterval(-1))
+		assert.Nil(t, err)
+
+		location, err := enricher.Lookup(net.ParseIP("89.160.20.130"))
+		assert.Nil(t, err)
+		assert.Equal(t, "Linköping", location.City, "record size %d", recordSize)
+		assert.Equal(t, "E", location.RegionCode, "record size %d", recordSize)
+
+		location, err = enricher.Lookup(net.ParseIP("2001:480:10::1"))
+		assert.Nil(t, err)
+		assert.Equal(t, "US", location.CountryCode, "record size %d", recordSize)
+
+		location, err = enricher.Lookup(net.ParseIP("89.160.20.127"))
+		assert.Nil(t, err)
+		assert.Nil(t, location, "record size %d", recordSize)
+		enricher.Close()
+	}
+}
+
+func TestEnricherExtractor(t *testing.T) {
+	enricher, _ := NewEnricher(testDatabasePath, ReloadInterval(-1))
+	defer enricher.Close()
+
+	clientIP, _ := extractor.ClientIP()
+	contextExtractor := extractor.NewContextExtractor(clientIP, enricher)
+
+	r, _ := http.NewRequest("GET", "http://example.com", nil)
+	r.RemoteAddr = "89.160.20.130:4000"
+	context := contextExtractor.Extract(r)
+	assert.Equal(t, "89.160.20.130", context[extractor.IP_CONTEXT_KEY])
+	assert.Equal(t, "SE", context[COUNTRY_CODE_CONTEXT_KEY])
+	assert.Equal(t, "Linköping", context[CITY_CONTEXT_KEY])
+
+	// Without IP, the context is left as is
+	context = extractor.NewContextExtractor(enricher).Extract(r)
+	assert.Equal(t, model.Context{}, context)
+}
+
+func TestEnricherReload(t *testing.T) {
+	dir, err := os.MkdirTemp("", "geo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "geo.mmdb")
+	writeDatabase := func(countryCode string, modTime time.Time) {
+		content := writeTestDatabase([]testNetwork{
+			{"81.2.69.0/24", map[string]interface{}{"country": map[string]interface{}{"iso_code": countryCode}}},
+		}, 24)
+		assert.Nil(t, os.WriteFile(path, content, 0644))
+		assert.Nil(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	now := time.Now()
+	writeDatabase("GB", now.Add(-time.Hour))
+	enricher, err := NewEnricher(path, ReloadInterval(10*time.Millisecond))
+	assert.Nil(t, err)
+	defer enricher.Close()
+
+	lookupCountry := func() string {
+		location, _ := enricher.Lookup(net.ParseIP("81.2.69.1"))
+		if location == nil {
+			return ""
+		}
+		return location.CountryCode
+	}
+	assert.Equal(t, "GB", lookupCountry())
+
+	writeDatabase("FR", now)
+	assert.Eventually(t, func() bool { return lookupCountry() == "FR" }, time.Second, 10*time.Millisecond)
+
+	// An invalid file does not replace the loaded database
+	enricher.Close()
+	assert.Nil(t, os.WriteFile(path, []byte("invalid"), 0644))
+	assert.NotNil(t, enricher.Reload())
+	assert.Equal(t, "FR", lookupCountry())
+
+	assert.Nil(t, os.Remove(path))
+	assert.NotNil(t, enricher.Reload())
+	assert.Equal(t, "FR", lookupCountry())
+}
@@ -0,0 +1,266 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the test database in testdata")
+
+var testDatabasePath = filepath.Join("testdata", "Flagship-Test-City.mmdb")
+
+func testCityRecord(continent, countryCode, countryEN, countryFR, regionCode, region, city, postalCode, timeZone string) map[string]interface{} {
+	return map[string]interface{}{
+		"continent": map[string]interface{}{"code": continent},
+		"country": map[string]interface{}{
+			"iso_code": countryCode,
+			"names":    map[string]interface{}{"en": countryEN, "fr": countryFR},
+		},
+		"subdivisions": []interface{}{map[string]interface{}{
+			"iso_code": regionCode,
+			"names":    map[string]interface{}{"en": region},
+		}},
+		"city":       map[string]interface{}{"names": map[string]interface{}{"en": city}},
+		"postal":     map[string]interface{}{"code": postalCode},
+		"location":   map[string]interface{}{"time_zone": timeZone, "latitude": 51.5142, "accuracy_radius": uint16(100)},
+		"is_anycast": false,
+	}
+}
+
+var testNetworks = []testNetwork{
+	{"81.2.69.0/24", testCityRecord("EU", "GB", "United Kingdom", "Royaume-Uni", "ENG", "England", "London", "E1", "Europe/London")},
+	{"89.160.20.128/25", testCityRecord("EU", "SE", "Sweden", "Suède", "E", "Östergötland County", "Linköping", "587 33", "Europe/Stockholm")},
+	{"2001:480::/32", testCityRecord("NA", "US", "United States", "États-Unis", "CA", "California", "San Diego", "92101", "America/Los_Angeles")},
+	{"2a02:cf40::/29", map[string]interface{}{"country": map[string]interface{}{"iso_code": "NO"}}},
+}
+
+// TestTestDatabase checks that the test database in testdata is up to date. Run with -update to regenerate it
+func TestTestDatabase(t *testing.T) {
+	content := writeTestDatabase(testNetworks, 24)
+	if *update {
+		assert.Nil(t, os.WriteFile(testDatabasePath, content, 0644))
+	}
+
+	existing, err := os.ReadFile(testDatabasePath)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(content, existing), "Test database is outdated, run the tests with -update")
+
+	// The test databases are valid for the MaxMind DB reader
+	for _, recordSize := range []int{24, 28, 32} {
+		reader, err := maxminddb.FromBytes(writeTestDatabase(testNetworks, recordSize))
+		assert.Nil(t, err)
+		assert.Nil(t, reader.Verify(), "record size %d", recordSize)
+		assert.Equal(t, uint(recordSize), reader.Metadata.RecordSize)
+	}
+}
+
+// testMetadataStartMarker precedes the metadata section at the end of a MaxMind DB file
+var testMetadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// Data section field types of the MaxMind DB format written by the test writer
+const (
+	typeExtended = 0
+	typeString   = 2
+	typeDouble   = 3
+	typeUint16   = 5
+	typeUint32   = 6
+	typeMap      = 7
+	typeUint64   = 9
+	typeArray    = 11
+	typeBool     = 14
+)
+
+// testNetwork represents a network record written in the test databases
+type testNetwork struct {
+	cidr   string
+	record map[string]interface{}
+}
+
+type testTrieNode struct {
+	children [2]*testTrieNode
+	records  [2]int
+}
+
+func newTestTrieNode() *testTrieNode {
+	return &testTrieNode{records: [2]int{-1, -1}}
+}
+
+// writeTestDatabase builds an IPv6 MaxMind DB file with records of 24, 28 or 32 bits. IPv4 networks are stored in the ::/96 subtree
+func writeTestDatabase(networks []testNetwork, recordSize int) []byte {
+	root := newTestTrieNode()
+	data := &bytes.Buffer{}
+	recordOffsets := []int{}
+
+	for _, n := range networks {
+		_, network, err := net.ParseCIDR(n.cidr)
+		if err != nil {
+			panic(err)
+		}
+		ones, _ := network.Mask.Size()
+		address := network.IP.To16()
+		if ipv4 := network.IP.To4(); ipv4 != nil {
+			address = append(make([]byte, 12), ipv4...)
+			ones += 96
+		}
+
+		recordOffsets = append(recordOffsets, data.Len())
+		encodeTestValue(data, n.record)
+
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := int(address[i>>3]>>(7-uint(i&7))) & 1
+			if i == ones-1 {
+				node.records[bit] = len(recordOffsets) - 1
+				break
+			}
+			if node.children[bit] == nil {
+				node.children[bit] = newTestTrieNode()
+			}
+			node = node.children[bit]
+		}
+	}
+
+	// Number the nodes in breadth-first order
+	nodes := []*testTrieNode{root}
+	indexes := map[*testTrieNode]int{root: 0}
+	for i := 0; i < len(nodes); i++ {
+		for _, child := range nodes[i].children {
+			if child != nil {
+				indexes[child] = len(nodes)
+				nodes = append(nodes, child)
+			}
+		}
+	}
+
+	nodeCount := len(nodes)
+	file := &bytes.Buffer{}
+	for _, node := range nodes {
+		values := [2]int{}
+		for bit := 0; bit < 2; bit++ {
+			values[bit] = nodeCount
+			if node.children[bit] != nil {
+				values[bit] = indexes[node.children[bit]]
+			} else if node.records[bit] != -1 {
+				values[bit] = nodeCount + 16 + recordOffsets[node.records[bit]]
+			}
+		}
+		left, right := values[0], values[1]
+
+		switch recordSize {
+		case 24:
+			file.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)})
+		case 28:
+			// The middle byte holds the 4 most significant bits of the left record, then of the right record
+			file.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(left>>24)<<4 | byte(right>>24)&0x0f, byte(right >> 16), byte(right >> 8), byte(right)})
+		case 32:
+			_ = binary.Write(file, binary.BigEndian, [2]uint32{uint32(left), uint32(right)})
+		default:
+			panic("record size not handled by the test writer")
+		}
+	}
+	file.Write(make([]byte, 16))
+	file.Write(data.Bytes())
+	file.Write(testMetadataStartMarker)
+	encodeTestValue(file, map[string]interface{}{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(recordSize),
+		"ip_version":                  uint16(6),
+		"database_type":               "Flagship-Test-City",
+		"languages":                   []interface{}{"en", "fr"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1700000000),
+		"description":                 map[string]interface{}{"en": "Flagship geo test database"},
+	})
+	return file.Bytes()
+}
+
+// encodeTestValue writes the value in the MaxMind DB data section format
+func encodeTestValue(buffer *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		writeTestControl(buffer, typeString, len(v))
+		buffer.WriteString(v)
+	case float64:
+		writeTestControl(buffer, typeDouble, 8)
+		_ = binary.Write(buffer, binary.BigEndian, math.Float64bits(v))
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		writeTestControl(buffer, typeBool, size)
+	case uint16:
+		writeTestUint(buffer, typeUint16, uint64(v))
+	case uint32:
+		writeTestUint(buffer, typeUint32, uint64(v))
+	case uint64:
+		writeTestUint(buffer, typeUint64, v)
+	case []interface{}:
+		writeTestControl(buffer, typeArray, len(v))
+		for _, item := range v {
+			encodeTestValue(buffer, item)
+		}
+	case map[string]interface{}:
+		writeTestControl(buffer, typeMap, len(v))
+		keys := []string{}
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			encodeTestValue(buffer, key)
+			encodeTestValue(buffer, v[key])
+		}
+	default:
+		panic("value type not handled by the test writer")
+	}
+}
+
+func writeTestUint(buffer *bytes.Buffer, fieldType int, value uint64) {
+	b := []byte{}
+	for ; value > 0; value >>= 8 {
+		b = append([]byte{byte(value)}, b...)
+	}
+	writeTestControl(buffer, fieldType, len(b))
+	buffer.Write(b)
+}
+
+func writeTestControl(buffer *bytes.Buffer, fieldType int, size int) {
+	typeBits := fieldType
+	if fieldType > 7 {
+		typeBits = typeExtended
+	}
+
+	sizeBits := size
+	sizeBytes := []byte{}
+	switch {
+	case size >= 65821:
+		sizeBits = 31
+		extra := size - 65821
+		sizeBytes = []byte{byte(extra >> 16), byte(extra >> 8), byte(extra)}
+	case size >= 285:
+		sizeBits = 30
+		extra := size - 285
+		sizeBytes = []byte{byte(extra >> 8), byte(extra)}
+	case size >= 29:
+		sizeBits = 29
+		sizeBytes = []byte{byte(size - 29)}
+	}
+
+	buffer.WriteByte(byte(typeBits<<5 | sizeBits))
+	if fieldType > 7 {
+		buffer.WriteByte(byte(fieldType - 7))
+	}
+	buffer.Write(sizeBytes)
+}
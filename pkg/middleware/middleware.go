@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	flagship "github.com/flagship-io/flagship-go-sdk/v2"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/client"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/extractor"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/logging"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+)
+
+var logger = logging.CreateLogger("FS Middleware")
+
+// DEFAULT_COOKIE_NAME is the default name of the visitor ID cookie
+const DEFAULT_COOKIE_NAME = "fs_visitor_id"
+
+// maxVisitorIDLength is the maximum length of a visitor ID read from the cookie
+const maxVisitorIDLength = 256
+
+// Middleware creates a Flagship visitor for each HTTP request, and stores it in the request context
+type Middleware struct {
+	client           *client.Client
+	cookieName       string
+	cookieMaxAge     time.Duration
+	cookiePath       string
+	cookieDomain     string
+	secureCookie     bool
+	contextExtractor *extractor.ContextExtractor
+	visitorOptions   []client.VisitorOptionBuilder
+	requestVisitor   func(r *http.Request) (string, []client.VisitorOptionBuilder)
+	sendPageView     bool
+	errorHandler     func(w http.ResponseWriter, r *http.Request, err error) bool
+}
+
+// CookieName sets the name of the visitor ID cookie. Defaults to fs_visitor_id
+func CookieName(name string) func(*Middleware) {
+	return func(m *Middleware) {
+		m.cookieName = name
+	}
+}
+
+// CookieMaxAge sets the lifetime of the visitor ID cookie. Defaults to 13 months
+func CookieMaxAge(maxAge time.Duration) func(*Middleware) {
+	return func(m *Middleware) {
+		m.cookieMaxAge = maxAge
+	}
+}
+
+// CookieDomain sets the path and domain of the visitor ID cookie. The path defaults to /
+func CookieDomain(path string, domain string) func(*Middleware) {
+	return func(m *Middleware) {
+		m.cookiePath = path
+		m.cookieDomain = domain
+	}
+}
+
+// SecureCookie sends the visitor ID cookie over HTTPS only
+func SecureCookie(secure bool) func(*Middleware) {
+	return func(m *Middleware) {
+		m.secureCookie = secure
+	}
+}
+
+// ContextExtractor sets the extractor building the visitor context from the request
+func ContextExtractor(contextExtractor *extractor.ContextExtractor) func(*Middleware) {
+	return func(m *Middleware) {
+		m.contextExtractor = contextExtractor
+	}
+}
+
+// VisitorOptions sets the options of the visitors created for the requests
+func VisitorOptions(options ...client.VisitorOptionBuilder) func(*Middleware) {
+	return func(m *Middleware) {
+		m.visitorOptions = options
+	}
+}
+
+// RequestVisitor sets the func returning the visitor ID and options of each request, for the applications identifying their visitors themselves.
+// The visitor ID cookie is then neither read nor set, and the options are added to the ones set with VisitorOptions
+func RequestVisitor(requestVisitor func(r *http.Request) (visitorID string, options []client.VisitorOptionBuilder)) func(*Middleware) {
+	return func(m *Middleware) {
+		m.requestVisitor = requestVisitor
+	}
+}
+
+// SendPageView sends a PAGEVIEW hit with the request URL for each request, in the background
+func SendPageView(enabled bool) func(*Middleware) {
+	return func(m *Middleware) {
+		m.sendPageView = enabled
+	}
+}
+
+// ErrorHandler sets the func called when the visitor cannot be created. If it returns false, the request is not handled further.
+// By default the error is logged and the request is handled without visitor in the context
+func ErrorHandler(errorHandler func(w http.ResponseWriter, r *http.Request, err error) bool) func(*Middleware) {
+	return func(m *Middleware) {
+		m.errorHandler = errorHandler
+	}
+}
+
+// New creates a middleware creating the visitors with the Flagship client
+func New(fsClient *client.Client, params ...func(*Middleware)) *Middleware {
+	m := &Middleware{
+		client:       fsClient,
+		cookieName:   DEFAULT_COOKIE_NAME,
+		cookieMaxAge: 13 * 30 * 24 * time.Hour,
+		cookiePath:   "/",
+		errorHandler: func(w http.ResponseWriter, r *http.Request, err error) bool {
+			logger.Error("Error when creating the request visitor: ", err)
+			return true
+		},
+	}
+
+	for _, param := range params {
+		param(m)
+	}
+	return m
+}
+
+// Handler wraps the next handler. It can be used with any router accepting func(http.Handler) http.Handler middlewares
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		visitor, err := m.createVisitor(w, r)
+		if err != nil {
+			if m.errorHandler(w, r, err) {
+				next.ServeHTTP(w, r)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(flagship.ContextWithVisitor(r.Context(), visitor)))
+	})
+}
+
+// HandlerFunc wraps the next handler func
+func (m *Middleware) HandlerFunc(next http.HandlerFunc) http.HandlerFunc {
+	return m.Handler(next).ServeHTTP
+}
+
+// createVisitor creates the visitor of the request, sets its ID cookie unless the request visitor is set, and synchronizes its flags
+func (m *Middleware) createVisitor(w http.ResponseWriter, r *http.Request) (*client.Visitor, error) {
+	visitorID := ""
+	options := m.visitorOptions
+	if m.requestVisitor != nil {
+		var requestOptions []client.VisitorOptionBuilder
+		visitorID, requestOptions = m.requestVisitor(r)
+		options = append(append([]client.VisitorOptionBuilder{}, m.visitorOptions...), requestOptions...)
+	} else if cookie, err := r.Cookie(m.cookieName); err == nil && len(cookie.Value) <= maxVisitorIDLength {
+		visitorID = cookie.Value
+	}
+
+	context := model.Context{}
+	if m.contextExtractor != nil {
+		context = m.contextExtractor.Extract(r)
+	}
+
+	visitor, err := m.client.NewVisitor(visitorID, context, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cookie is set on every response, so that its lifetime is renewed for the returning visitors
+	if m.requestVisitor == nil {
+		http.SetCookie(w, &http.Cookie{
+			Name:     m.cookieName,
+			Value:    visitor.GetID(),
+			Path:     m.cookiePath,
+			Domain:   m.cookieDomain,
+			MaxAge:   int(m.cookieMaxAge.Seconds()),
+			Secure:   m.secureCookie,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	// The visitor is kept with the default flag values if the synchronization fails
+	if err := visitor.SynchronizeModificationsAsync(r.Context()).Wait(); err != nil {
+		logger.Warnf("Error when synchronizing the request visitor flags: %v", err)
+	}
+
+	if m.sendPageView {
+		hit := &model.PageHit{BaseHit: model.BaseHit{
+			DocumentLocation: getRequestURL(r),
+			DocumentReferrer: r.Referer(),
+		}}
+		hit.UserIP, _ = context[extractor.IP_CONTEXT_KEY].(string)
+		hit.UserLanguage, _ = context[extractor.LANGUAGE_CONTEXT_KEY].(string)
+		go func() {
+			if err := visitor.SendHit(hit); err != nil {
+				logger.Warnf("Error when sending the request page view: %v", err)
+			}
+		}()
+	}
+	return visitor, nil
+}
+
+// getRequestURL returns the absolute URL of the request
+func getRequestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.RequestURI())
+}
@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	flagship "github.com/flagship-io/flagship-go-sdk/v2"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/client"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/decisionapi"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/extractor"
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEnvID = "test_env_id"
+var testAPIKey = "test_api_key"
+
+type recordingTrackingAPIClient struct {
+	mu         sync.Mutex
+	hits       []model.HitInterface
+	visitorIDs []string
+}
+
+func (c *recordingTrackingAPIClient) SendHit(visitorID string, anonymousID *string, hit model.HitInterface) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits = append(c.hits, hit)
+	c.visitorIDs = append(c.visitorIDs, visitorID)
+	return nil
+}
+func (*recordingTrackingAPIClient) ActivateCampaign(request model.ActivationHit) error { return nil }
+func (*recordingTrackingAPIClient) SendEvent(request model.Event) error                { return nil }
+
+func (c *recordingTrackingAPIClient) getHits() []model.HitInterface {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]model.HitInterface{}, c.hits...)
+}
+
+// createTestClient creates a client whose Decision API always returns the flag "color" with value "red"
+func createTestClient(t *testing.T, trackingAPIClient *recordingTrackingAPIClient) *client.Client {
+	decisionServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, _ := json.Marshal(model.APIClientResponse{
+			Campaigns: []model.Campaign{{
+				ID:               "cid",
+				VariationGroupID: "vgid",
+				Variation: model.ClientVariation{
+					ID: "vid",
+					Modifications: model.Modification{
+						Type:  "FLAG",
+						Value: map[string]interface{}{"color": "red"},
+					},
+				},
+			}},
+		})
+		_, _ = w.Write(response)
+	}))
+	t.Cleanup(decisionServer.Close)
+
+	fsClient, err := flagship.Start(testEnvID, testAPIKey,
+		client.WithDecisionAPI(decisionapi.APIUrl(decisionServer.URL)),
+		client.WithTrackingAPIClient(trackingAPIClient))
+	assert.Nil(t, err)
+	return fsClient
+}
+
+func TestMiddleware(t *testing.T) {
+	trackingAPIClient := &recordingTrackingAPIClient{}
+	fsClient := createTestClient(t, trackingAPIClient)
+
+	var requestVisitor *client.Visitor
+	handler := New(fsClient).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		visitor, ok := flagship.VisitorFromContext(r.Context())
+		assert.True(t, ok)
+		requestVisitor = visitor
+	})
+
+	// A new visitor ID is generated and set in the cookie
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", "http://example.com/products", nil))
+	assert.NotNil(t, requestVisitor)
+	color, _ := requestVisitor.GetModificationString("color", "blue", false)
+	assert.Equal(t, "red", color)
+
+	cookies := recorder.Result().Cookies()
+	assert.Equal(t, 1, len(cookies))
+	assert.Equal(t, DEFAULT_COOKIE_NAME, cookies[0].Name)
+	assert.Equal(t, requestVisitor.GetID(), cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+
+	// The visitor ID of the cookie is reused, and the cookie lifetime is renewed
+	recorder = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/products", nil)
+	r.AddCookie(&http.Cookie{Name: DEFAULT_COOKIE_NAME, Value: "cookie_vid"})
+	handler(recorder, r)
+	assert.Equal(t, "cookie_vid", requestVisitor.GetID())
+	cookies = recorder.Result().Cookies()
+	assert.Equal(t, 1, len(cookies))
+	assert.Equal(t, "cookie_vid", cookies[0].Value)
+	assert.Equal(t, 13*30*24*3600, cookies[0].MaxAge)
+
+	// No page view is sent by default
+	assert.Equal(t, 0, len(trackingAPIClient.getHits()))
+
+	_, ok := flagship.VisitorFromContext(r.Context())
+	assert.False(t, ok)
+}
+
+func TestMiddlewareOptions(t *testing.T) {
+	trackingAPIClient := &recordingTrackingAPIClient{}
+	fsClient := createTestClient(t, trackingAPIClient)
+
+	var requestVisitor *client.Visitor
+	handler := New(fsClient,
+		CookieName("vid"),
+		CookieMaxAge(24*time.Hour),
+		CookieDomain("/shop", "example.com"),
+		SecureCookie(true),
+		ContextExtractor(extractor.NewContextExtractor(
+			extractor.AcceptLanguage(),
+			extractor.Headers(map[string]string{"X-Plan": "plan"}),
+		)),
+		VisitorOptions(client.WithConsent(true)),
+		SendPageView(true),
+	).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestVisitor, _ = flagship.VisitorFromContext(r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/shop?page=2", nil)
+	r.Header.Set("X-Plan", "premium")
+	r.Header.Set("Accept-Language", "fr-FR")
+	r.Header.Set("Referer", "http://example.com/")
+	handler.ServeHTTP(recorder, r)
+
+	assert.Equal(t, "premium", requestVisitor.GetContext()["plan"])
+
+	cookie := recorder.Result().Cookies()[0]
+	assert.Equal(t, "vid", cookie.Name)
+	assert.Equal(t, "/shop", cookie.Path)
+	assert.Equal(t, "example.com", cookie.Domain)
+	assert.Equal(t, 24*60*60, cookie.MaxAge)
+	assert.True(t, cookie.Secure)
+
+	// The page view is sent in the background
+	assert.Eventually(t, func() bool { return len(trackingAPIClient.getHits()) == 1 }, time.Second, 5*time.Millisecond)
+	hit := trackingAPIClient.getHits()[0].(*model.PageHit)
+	assert.Equal(t, "http://example.com/shop?page=2", hit.DocumentLocation)
+	assert.Equal(t, "http://example.com/", hit.DocumentReferrer)
+	assert.Equal(t, "fr-FR", hit.UserLanguage)
+	assert.Equal(t, requestVisitor.GetID(), trackingAPIClient.visitorIDs[0])
+}
+
+func TestMiddlewareRequestVisitor(t *testing.T) {
+	fsClient := createTestClient(t, &recordingTrackingAPIClient{})
+
+	var requestVisitor *client.Visitor
+	handler := New(fsClient,
+		VisitorOptions(client.WithConsent(false)),
+		RequestVisitor(func(r *http.Request) (string, []client.VisitorOptionBuilder) {
+			return r.Header.Get("X-User"), []client.VisitorOptionBuilder{client.WithAuthenticated(true), client.WithAnonymousID("anonymous_vid")}
+		}),
+	).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestVisitor, _ = flagship.VisitorFromContext(r.Context())
+	})
+
+	// The request visitor replaces the visitor ID cookie, which is not set
+	recorder := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-User", "user_vid")
+	r.AddCookie(&http.Cookie{Name: DEFAULT_COOKIE_NAME, Value: "cookie_vid"})
+	handler(recorder, r)
+	assert.Equal(t, "user_vid", requestVisitor.GetID())
+	assert.Equal(t, "anonymous_vid", *requestVisitor.GetAnonymousID())
+	assert.False(t, requestVisitor.HasConsented())
+	assert.Equal(t, 0, len(recorder.Result().Cookies()))
+
+	color, _ := requestVisitor.GetModificationString("color", "blue", false)
+	assert.Equal(t, "red", color)
+}
+
+func TestMiddlewareError(t *testing.T) {
+	fsClient := createTestClient(t, &recordingTrackingAPIClient{})
+
+	// The visitor creation fails as the context is invalid
+	invalidContext := extractor.NewContextExtractor(extractor.ExtractorFunc(func(r *http.Request, context model.Context) {
+		context["invalid"] = errors.New("invalid")
+	}))
+
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		_, ok := flagship.VisitorFromContext(r.Context())
+		assert.False(t, ok)
+	})
+
+	New(fsClient, ContextExtractor(invalidContext)).Handler(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.True(t, called)
+
+	called = false
+	recorder := httptest.NewRecorder()
+	New(fsClient, ContextExtractor(invalidContext), ErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) bool {
+		w.WriteHeader(http.StatusInternalServerError)
+		return false
+	})).Handler(next).ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.False(t, called)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
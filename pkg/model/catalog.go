@@ -0,0 +1,18 @@
+package model
+
+// FlagMetadata represents a flag found in the environment configuration
+type FlagMetadata struct {
+	Key            string
+	Types          []string
+	ReferenceValue interface{}
+	Campaigns      []FlagCampaignMetadata
+	ContextKeys    []string
+}
+
+// FlagCampaignMetadata represents a campaign in which a flag appears
+type FlagCampaignMetadata struct {
+	ID                string
+	Slug              string
+	Type              string
+	VariationGroupIDs []string
+}
@@ -4,9 +4,38 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// CONSENT_CONTEXT_KEY is reserved so that the visitor consent can only be set with the visitor options
+const CONSENT_CONTEXT_KEY = "fs_consent"
+
+// Predefined context keys filled by the SDK for every visitor
+const (
+	FS_CLIENT_CONTEXT_KEY  = "fs_client"
+	FS_VERSION_CONTEXT_KEY = "fs_version"
+	OS_NAME_CONTEXT_KEY    = "sdk_osName"
+	HOSTNAME_CONTEXT_KEY   = "sdk_hostname"
+)
+
+// FS_CLIENT_NAME is the value of the fs_client predefined context key
+const FS_CLIENT_NAME = "go"
+
+// reservedContextKeys are the context keys that can only be set by the SDK, with the value set by the SDK.
+// The predefined keys are valid with the SDK value, so that a visitor context can be passed back as is
+var reservedContextKeys = map[string]interface{}{
+	FS_CLIENT_CONTEXT_KEY:  FS_CLIENT_NAME,
+	FS_VERSION_CONTEXT_KEY: utils.PKG_VERSION,
+	CONSENT_CONTEXT_KEY:    nil,
+}
+
+// IsReservedContextKey returns true if the context key can only be set by the SDK
+func IsReservedContextKey(key string) bool {
+	_, ok := reservedContextKeys[key]
+	return ok
+}
+
 // Context represents a visitor context object
 type Context map[string]interface{}
 
@@ -15,6 +44,13 @@ func (c Context) Validate() []error {
 	errorList := []error{}
 
 	for key, val := range c {
+		if sdkValue, ok := reservedContextKeys[key]; ok {
+			if sdkValue == nil || val != sdkValue {
+				errorList = append(errorList, fmt.Errorf("Context key %s is reserved to the SDK", key))
+			}
+			continue
+		}
+
 		_, okBool := val.(bool)
 		_, okString := val.(string)
 		_, okFloat64 := val.(float64)
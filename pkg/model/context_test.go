@@ -3,6 +3,8 @@ package model
 import (
 	"errors"
 	"testing"
+
+	"github.com/flagship-io/flagship-go-sdk/v2/pkg/utils"
 )
 
 func TestValidate(t *testing.T) {
@@ -20,3 +22,20 @@ func TestValidate(t *testing.T) {
 		t.Error("Wrong context variable should raise an error")
 	}
 }
+
+func TestValidateReservedKeys(t *testing.T) {
+	context := Context{FS_CLIENT_CONTEXT_KEY: "python", FS_VERSION_CONTEXT_KEY: "v1", CONSENT_CONTEXT_KEY: true}
+	if errs := context.Validate(); len(errs) != 3 {
+		t.Errorf("Reserved context keys should raise errors. Got %v", errs)
+	}
+
+	context = Context{FS_CLIENT_CONTEXT_KEY: FS_CLIENT_NAME, FS_VERSION_CONTEXT_KEY: utils.PKG_VERSION}
+	if errs := context.Validate(); len(errs) != 0 {
+		t.Errorf("Reserved context keys with the SDK value should be valid. Got %v", errs)
+	}
+
+	context = Context{OS_NAME_CONTEXT_KEY: "linux", HOSTNAME_CONTEXT_KEY: "host"}
+	if errs := context.Validate(); len(errs) != 0 {
+		t.Errorf("Predefined context keys that are not reserved should be valid. Got %v", errs)
+	}
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Limits of the custom dimensions and metrics of the hits
+const (
+	CUSTOM_PARAMETER_MAX_INDEX  = 200
+	CUSTOM_DIMENSION_MAX_LENGTH = 150
+)
+
+// SetCustomDimension sets the custom dimension of the hit at the index, from 1 to 200
+func (b *BaseHit) SetCustomDimension(index int, value string) {
+	if b.CustomDimensions == nil {
+		b.CustomDimensions = map[int]string{}
+	}
+	b.CustomDimensions[index] = value
+}
+
+// SetCustomMetric sets the custom metric of the hit at the index, from 1 to 200
+func (b *BaseHit) SetCustomMetric(index int, value float64) {
+	if b.CustomMetrics == nil {
+		b.CustomMetrics = map[int]float64{}
+	}
+	b.CustomMetrics[index] = value
+}
+
+func (b *BaseHit) validateCustomParameters() []error {
+	errorsList := []error{}
+	for index, value := range b.CustomDimensions {
+		if index < 1 || index > CUSTOM_PARAMETER_MAX_INDEX {
+			errorsList = append(errorsList, fmt.Errorf("Custom dimension index %d should be between 1 and %d", index, CUSTOM_PARAMETER_MAX_INDEX))
+		}
+		if value == "" {
+			errorsList = append(errorsList, fmt.Errorf("Custom dimension %d should not be empty", index))
+		}
+		if len(value) > CUSTOM_DIMENSION_MAX_LENGTH {
+			errorsList = append(errorsList, fmt.Errorf("Custom dimension %d should not be longer than %d bytes", index, CUSTOM_DIMENSION_MAX_LENGTH))
+		}
+	}
+
+	for index, value := range b.CustomMetrics {
+		if index < 1 || index > CUSTOM_PARAMETER_MAX_INDEX {
+			errorsList = append(errorsList, fmt.Errorf("Custom metric index %d should be between 1 and %d", index, CUSTOM_PARAMETER_MAX_INDEX))
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			errorsList = append(errorsList, fmt.Errorf("Custom metric %d should be a finite number", index))
+		}
+	}
+	return errorsList
+}
+
+// marshalWithCustomParameters marshals the hit, and adds the custom dimensions and metrics as indexed parameters
+func (b *BaseHit) marshalWithCustomParameters(hit interface{}) ([]byte, error) {
+	data, err := json.Marshal(hit)
+	if err != nil || (len(b.CustomDimensions) == 0 && len(b.CustomMetrics) == 0) {
+		return data, err
+	}
+
+	parameters := map[string]interface{}{}
+	for index, value := range b.CustomDimensions {
+		parameters[fmt.Sprintf("cd%d", index)] = value
+	}
+	for index, value := range b.CustomMetrics {
+		parameters[fmt.Sprintf("cm%d", index)] = value
+	}
+	parametersData, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both are JSON objects, so the parameters are inserted before the closing brace of the hit
+	if len(data) == 2 {
+		return parametersData, nil
+	}
+	return append(append(data[:len(data)-1], ','), parametersData[1:]...), nil
+}
+
+// The hits embedding BaseHit marshal their custom parameters. The local types drop the MarshalJSON method to avoid recursion
+
+// MarshalJSON marshals the hit with its custom dimensions and metrics
+func (b PageHit) MarshalJSON() ([]byte, error) {
+	type pageHit PageHit
+	return b.marshalWithCustomParameters(pageHit(b))
+}
+
+// MarshalJSON marshals the hit with its custom dimensions and metrics
+func (b ScreenHit) MarshalJSON() ([]byte, error) {
+	type screenHit ScreenHit
+	return b.marshalWithCustomParameters(screenHit(b))
+}
+
+// MarshalJSON marshals the hit with its custom dimensions and metrics
+func (b EventHit) MarshalJSON() ([]byte, error) {
+	type eventHit EventHit
+	return b.marshalWithCustomParameters(eventHit(b))
+}
+
+// MarshalJSON marshals the hit with its custom dimensions and metrics
+func (b TransactionHit) MarshalJSON() ([]byte, error) {
+	type transactionHit TransactionHit
+	return b.marshalWithCustomParameters(transactionHit(b))
+}
+
+// MarshalJSON marshals the hit with its custom dimensions and metrics
+func (b ItemHit) MarshalJSON() ([]byte, error) {
+	type itemHit ItemHit
+	return b.marshalWithCustomParameters(itemHit(b))
+}
+
+// MarshalJSON marshals the hit with its custom dimensions and metrics
+func (b BatchHit) MarshalJSON() ([]byte, error) {
+	type batchHit BatchHit
+	return b.marshalWithCustomParameters(batchHit(b))
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomParametersMarshal(t *testing.T) {
+	hit := &EventHit{Action: "action"}
+	data, err := json.Marshal(hit)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"ea":"action"}`, string(data))
+
+	hit.SetCustomDimension(1, "premium")
+	hit.SetCustomDimension(12, "cohort_b")
+	hit.SetCustomMetric(3, 2.5)
+	data, err = json.Marshal(hit)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"ea":"action","cd1":"premium","cd12":"cohort_b","cm3":2.5}`, string(data))
+
+	// The hits are marshalled the same way by value
+	data, err = json.Marshal(*hit)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"ea":"action","cd1":"premium","cd12":"cohort_b","cm3":2.5}`, string(data))
+
+	page := &PageHit{BaseHit: BaseHit{CustomMetrics: map[int]float64{1: 10}}}
+	data, err = json.Marshal(page)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"cm1":10}`, string(data))
+
+	// The custom parameters of the hits of a batch are kept
+	batch := &BatchHit{BaseHit: BaseHit{VisitorID: testVisitorID}}
+	batch.SetCustomDimension(2, "batch")
+	item := &ItemHit{TransactionID: "tid", Name: "name"}
+	item.SetCustomDimension(1, "item")
+	batch.AddHit(item)
+	batch.AddHit(&TransactionHit{TransactionID: "tid", Affiliation: "aff"})
+	batch.AddHit(&ScreenHit{BaseHit: BaseHit{CustomDimensions: map[int]string{5: "screen"}}})
+	data, err = json.Marshal(batch)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"vid":"test_visitor_id","h":[{"tid":"tid","in":"name","cd1":"item"},{"tid":"tid","ta":"aff"},{"cd5":"screen"}],"cd2":"batch"}`, string(data))
+}
+
+func TestCustomParametersValidate(t *testing.T) {
+	hit := &EventHit{Action: "action"}
+	hit.SetBaseInfos(testEnvID, testVisitorID, nil)
+	hit.SetCustomDimension(1, "premium")
+	hit.SetCustomMetric(CUSTOM_PARAMETER_MAX_INDEX, 3)
+	assert.Empty(t, hit.Validate())
+
+	hit.SetCustomDimension(0, "value")
+	hit.SetCustomDimension(2, "")
+	hit.SetCustomDimension(3, strings.Repeat("a", CUSTOM_DIMENSION_MAX_LENGTH+1))
+	hit.SetCustomMetric(CUSTOM_PARAMETER_MAX_INDEX+1, 1)
+	hit.SetCustomMetric(4, math.NaN())
+	hit.SetCustomMetric(5, math.Inf(1))
+	assert.Equal(t, 6, len(hit.Validate()))
+}
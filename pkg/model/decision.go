@@ -18,17 +18,29 @@ type APIOptions struct {
 
 // APIClientRequest represents the API client informations
 type APIClientRequest struct {
-	VisitorID   string  `json:"visitor_id"`
-	AnonymousID *string `json:"anonymous_id"`
-	Context     Context `json:"context"`
-	TriggerHit  bool    `json:"trigger_hit"`
+	VisitorID      string  `json:"visitor_id"`
+	AnonymousID    *string `json:"anonymous_id"`
+	Context        Context `json:"context"`
+	TriggerHit     bool    `json:"trigger_hit"`
+	VisitorConsent *bool   `json:"visitor_consent,omitempty"`
 }
 
+// DecisionSource represents the source that computed a decision response
+type DecisionSource string
+
+// The different decision sources
+const (
+	SOURCE_DECISION_API DecisionSource = "DECISION_API"
+	SOURCE_BUCKETING    DecisionSource = "BUCKETING"
+	SOURCE_CACHE        DecisionSource = "CACHE"
+)
+
 // APIClientResponse represents a decision response
 type APIClientResponse struct {
-	VisitorID string     `json:"visitorId"`
-	Panic     bool       `json:"panic"`
-	Campaigns []Campaign `json:"campaigns"`
+	VisitorID string         `json:"visitorId"`
+	Panic     bool           `json:"panic"`
+	Campaigns []Campaign     `json:"campaigns"`
+	Source    DecisionSource `json:"-"`
 }
 
 // Campaign represents a decision campaign
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"reflect"
 	"time"
 )
 
@@ -53,6 +54,9 @@ type BaseHit struct {
 	CurrentSessionTimestamp int64     `json:"cst,omitempty"`
 	SessionNumber           int64     `json:"sn,omitempty"`
 	CreatedAt               time.Time `json:"-"`
+	// CustomDimensions and CustomMetrics are sent as the cd<index> and cm<index> parameters
+	CustomDimensions map[int]string  `json:"-"`
+	CustomMetrics    map[int]float64 `json:"-"`
 }
 
 // SetBaseInfos sets the mandatory information for the hit
@@ -100,9 +104,20 @@ func (b *BaseHit) validateBase() []error {
 		errorsList = append(errorsList, errors.New("Document location must be empty for this type of hit"))
 	}
 
+	errorsList = append(errorsList, b.validateCustomParameters()...)
 	return errorsList
 }
 
+// SetSessionInfos sets the current session timestamp and the session number of the hit, if they are not already set
+func (b *BaseHit) SetSessionInfos(sessionTimestamp int64, sessionNumber int64) {
+	if b.CurrentSessionTimestamp == 0 {
+		b.CurrentSessionTimestamp = sessionTimestamp
+	}
+	if b.SessionNumber == 0 {
+		b.SessionNumber = sessionNumber
+	}
+}
+
 // ComputeQueueTime computes hit queue time
 func (b *BaseHit) ComputeQueueTime() {
 	b.QueueTime = int64((time.Since(b.CreatedAt)).Milliseconds())
@@ -148,6 +163,9 @@ func (b *ScreenHit) Validate() []error {
 	return b.validateBase()
 }
 
+// CONSENT_HIT_ACTION is the action of the event hits sending the visitor consent
+const CONSENT_HIT_ACTION = "fs_consent"
+
 // EventHit represents an event hit for the datacollect
 type EventHit struct {
 	BaseHit
@@ -172,6 +190,21 @@ func (b *EventHit) Validate() []error {
 	return errorsList
 }
 
+// NewConsentHit creates the event hit sending the visitor consent
+func NewConsentHit(hasConsented bool) *EventHit {
+	return &EventHit{
+		Action:   CONSENT_HIT_ACTION,
+		Category: "User Engagement",
+		Label:    fmt.Sprintf("go:%v", hasConsented),
+	}
+}
+
+// IsConsentHit checks if the hit is exactly the event hit sending the visitor consent, as built by NewConsentHit
+func IsConsentHit(hit HitInterface) bool {
+	eventHit, ok := hit.(*EventHit)
+	return ok && (reflect.DeepEqual(eventHit, NewConsentHit(true)) || reflect.DeepEqual(eventHit, NewConsentHit(false)))
+}
+
 // TransactionHit represents a transaction hit for the datacollect
 type TransactionHit struct {
 	BaseHit
@@ -6,3 +6,8 @@ type HitInterface interface {
 	SetBaseInfos(envID string, visitorID string, anonymousID *string)
 	ComputeQueueTime()
 }
+
+// SessionHitInterface express the interface for the hits carrying the visitor session
+type SessionHitInterface interface {
+	SetSessionInfos(sessionTimestamp int64, sessionNumber int64)
+}
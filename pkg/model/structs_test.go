@@ -66,6 +66,17 @@ func TestSetBaseInfos(t *testing.T) {
 	assert.Equal(t, testVisitorID, b.CustomerID)
 }
 
+func TestSetSessionInfos(t *testing.T) {
+	var hit SessionHitInterface = &PageHit{}
+	hit.SetSessionInfos(1672567200, 3)
+	assert.Equal(t, int64(1672567200), hit.(*PageHit).CurrentSessionTimestamp)
+	assert.Equal(t, int64(3), hit.(*PageHit).SessionNumber)
+
+	hit.SetSessionInfos(1672570000, 4)
+	assert.Equal(t, int64(1672567200), hit.(*PageHit).CurrentSessionTimestamp)
+	assert.Equal(t, int64(3), hit.(*PageHit).SessionNumber)
+}
+
 func TestValidatePage(t *testing.T) {
 	b := PageHit{
 		BaseHit: BaseHit{},